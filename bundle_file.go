@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// parseBundle parses a bundle for the trust domain in any of the following
+// formats:
+//
+//	PEM       X.509 authorities as concatenated CERTIFICATE blocks
+//	JWKS      a SPIFFE bundle (as served by a bundle endpoint)
+//	protojson a SPIRE API Bundle (as returned by the Bundle API)
+//
+// If the trust domain is empty, a bundle for any trust domain is accepted.
+// PEM and JWKS bundles, which don't name their trust domain, are then
+// returned without one.
+func parseBundle(data []byte, trustDomain string) (*types.Bundle, error) {
+	// PEM and JWKS bundles are parsed for a placeholder trust domain when
+	// the trust domain is unknown.
+	td := spiffeid.RequireTrustDomainFromString("unknown")
+	name := ""
+	if trustDomain != "" {
+		var err error
+		td, err = spiffeid.TrustDomainFromString(trustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust domain %q: %v", trustDomain, err)
+		}
+		name = td.Name()
+	}
+	withTrustDomain := func(bundle *types.Bundle, err error) (*types.Bundle, error) {
+		if err == nil {
+			bundle.TrustDomain = name
+		}
+		return bundle, err
+	}
+
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("-----BEGIN")):
+		bundle, err := x509bundle.Parse(td, data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse PEM bundle: %v", err)
+		}
+		return withTrustDomain(bundleFromSPIFFEBundle(spiffebundle.FromX509Bundle(bundle)))
+	case bytes.HasPrefix(data, []byte("{")):
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("unable to parse bundle: %v", err)
+		}
+		if _, ok := fields["keys"]; ok {
+			bundle, err := spiffebundle.Parse(td, data)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse SPIFFE bundle: %v", err)
+			}
+			return withTrustDomain(bundleFromSPIFFEBundle(bundle))
+		}
+		bundle := new(types.Bundle)
+		if err := protojson.Unmarshal(data, bundle); err != nil {
+			return nil, fmt.Errorf("unable to parse bundle: %v", err)
+		}
+		switch {
+		case name == "":
+		case bundle.TrustDomain == "":
+			bundle.TrustDomain = name
+		case bundle.TrustDomain != name:
+			return nil, fmt.Errorf("bundle is for trust domain %q; expected %q", bundle.TrustDomain, name)
+		}
+		return bundle, nil
+	default:
+		return nil, fmt.Errorf("unrecognized bundle format (expected PEM, JWKS or protojson)")
+	}
+}
+
+func bundleFromSPIFFEBundle(bundle *spiffebundle.Bundle) (*types.Bundle, error) {
+	out := &types.Bundle{
+		TrustDomain: bundle.TrustDomain().Name(),
+	}
+	for _, cert := range bundle.X509Authorities() {
+		out.X509Authorities = append(out.X509Authorities, &types.X509Certificate{Asn1: cert.Raw})
+	}
+	jwtAuthorities := bundle.JWTAuthorities()
+	keyIDs := make([]string, 0, len(jwtAuthorities))
+	for keyID := range jwtAuthorities {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+	for _, keyID := range keyIDs {
+		publicKey := jwtAuthorities[keyID]
+		der, err := x509.MarshalPKIXPublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal JWT authority %q: %v", keyID, err)
+		}
+		out.JwtAuthorities = append(out.JwtAuthorities, &types.JWTKey{PublicKey: der, KeyId: keyID})
+	}
+	if refreshHint, ok := bundle.RefreshHint(); ok {
+		out.RefreshHint = int64(refreshHint.Seconds())
+	}
+	if sequenceNumber, ok := bundle.SequenceNumber(); ok {
+		out.SequenceNumber = sequenceNumber
+	}
+	return out, nil
+}
+
+// spiffeBundleFromBundle converts a SPIRE API bundle into a SPIFFE bundle
+// (e.g. to marshal it as JWKS).
+func spiffeBundleFromBundle(bundle *types.Bundle) (*spiffebundle.Bundle, error) {
+	td, err := spiffeid.TrustDomainFromString(bundle.TrustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain %q: %v", bundle.TrustDomain, err)
+	}
+	out := spiffebundle.New(td)
+	for i, authority := range bundle.X509Authorities {
+		cert, err := x509.ParseCertificate(authority.Asn1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid X.509 authority %d: %v", i, err)
+		}
+		out.AddX509Authority(cert)
+	}
+	for _, authority := range bundle.JwtAuthorities {
+		publicKey, err := x509.ParsePKIXPublicKey(authority.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT authority %q: %v", authority.KeyId, err)
+		}
+		if err := out.AddJWTAuthority(authority.KeyId, publicKey); err != nil {
+			return nil, fmt.Errorf("invalid JWT authority %q: %v", authority.KeyId, err)
+		}
+	}
+	if bundle.RefreshHint != 0 {
+		out.SetRefreshHint(time.Duration(bundle.RefreshHint) * time.Second)
+	}
+	if bundle.SequenceNumber != 0 {
+		out.SetSequenceNumber(bundle.SequenceNumber)
+	}
+	return out, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func AgentCommand() *cobra.Command {
+	config := new(rpcConfig)
+	cmd := &cobra.Command{Use: "agent", Short: "Manages attested agents via the Agent API"}
+	addServerFlags(cmd.PersistentFlags(), config)
+	addConnectionFlags(cmd.PersistentFlags(), config)
+	cmd.PersistentFlags().DurationVarP(&config.timeout, "timeout", "", time.Minute, "Timeout for the command, including any paging")
+	cmd.AddCommand(AgentListCommand(config))
+	cmd.AddCommand(AgentBanCommand(config))
+	cmd.AddCommand(AgentEvictCommand(config))
+	return cmd
+}
+
+// listAgents lists all agents matching the filter, following pagination.
+func listAgents(ctx context.Context, client agentv1.AgentClient, filter *agentv1.ListAgentsRequest_Filter) ([]*types.Agent, error) {
+	var agents []*types.Agent
+	req := &agentv1.ListAgentsRequest{Filter: filter}
+	for {
+		resp, err := client.ListAgents(ctx, req)
+		if err != nil {
+			return nil, newRPCError("ListAgents", err)
+		}
+		agents = append(agents, resp.Agents...)
+		if resp.NextPageToken == "" {
+			return agents, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// formatAgents renders agents as a table or, when json is requested, as the
+// given message (which carries the agents).
+func formatAgents(format string, agents []*types.Agent, m proto.Message, now time.Time) ([]byte, error) {
+	if format == outputJSON {
+		return append(marshalProtoJSON(m), '\n'), nil
+	}
+
+	rows := make([][]string, 0, len(agents))
+	for _, agent := range agents {
+		rows = append(rows, []string{
+			formatSPIFFEID(agent.Id),
+			agent.AttestationType,
+			agent.X509SvidSerialNumber,
+			time.Unix(agent.X509SvidExpiresAt, 0).UTC().Format(time.RFC3339),
+			formatExpiresIn(time.Unix(agent.X509SvidExpiresAt, 0), now),
+			strconv.FormatBool(agent.Banned),
+		})
+	}
+	out := new(bytes.Buffer)
+	if err := writeTable(out, []string{"SPIFFE ID", "ATTESTATION TYPE", "SERIAL", "EXPIRES AT", "EXPIRES IN", "BANNED"}, rows); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// formatExpiresIn formats the time left until expiresAt.
+func formatExpiresIn(expiresAt, now time.Time) string {
+	ttl := expiresAt.Sub(now).Truncate(time.Second)
+	if ttl <= 0 {
+		return "expired " + (-ttl).String() + " ago"
+	}
+	return ttl.String()
+}
+
+// isStale returns true if the agent's X509-SVID has expired.
+func isStale(agent *types.Agent, now time.Time) bool {
+	return !now.Before(time.Unix(agent.X509SvidExpiresAt, 0))
+}
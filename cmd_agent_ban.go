@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+)
+
+func AgentBanCommand(config *rpcConfig) *cobra.Command {
+	impl := &agentBan{config: config}
+	cmd := &cobra.Command{
+		Use:   "ban",
+		Short: "Bans an agent, preventing it from re-attesting",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.id, "id", "", "", "SPIFFE ID of the agent")
+	_ = cmd.RegisterFlagCompletionFunc("id", completeAgentIDs(config))
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+type agentBan struct {
+	config *rpcConfig
+	id     string
+}
+
+func (cmd *agentBan) Run(ctx context.Context, args []string) ([]byte, error) {
+	id, err := parseSPIFFEID(cmd.id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := agentv1.NewAgentClient(conn).BanAgent(ctx, &agentv1.BanAgentRequest{Id: id}); err != nil {
+		return nil, newRPCError("BanAgent", err)
+	}
+	return []byte(fmt.Sprintf("Banned %s\n", cmd.id)), nil
+}
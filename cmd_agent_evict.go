@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+)
+
+func AgentEvictCommand(config *rpcConfig) *cobra.Command {
+	impl := &agentEvict{config: config}
+	cmd := &cobra.Command{
+		Use:   "evict",
+		Short: "Evicts an agent, forcing it to re-attest",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.id, "id", "", "", "SPIFFE ID of the agent")
+	_ = cmd.RegisterFlagCompletionFunc("id", completeAgentIDs(config))
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+type agentEvict struct {
+	config *rpcConfig
+	id     string
+}
+
+func (cmd *agentEvict) Run(ctx context.Context, args []string) ([]byte, error) {
+	id, err := parseSPIFFEID(cmd.id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := agentv1.NewAgentClient(conn).DeleteAgent(ctx, &agentv1.DeleteAgentRequest{Id: id}); err != nil {
+		return nil, newRPCError("DeleteAgent", err)
+	}
+	return []byte(fmt.Sprintf("Evicted %s\n", cmd.id)), nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// expiresBeforeLayout is the time layout the Agent API expects for the
+// expires-before filter.
+const expiresBeforeLayout = "2006-01-02 15:04:05 -0700 -07"
+
+func AgentListCommand(config *rpcConfig) *cobra.Command {
+	impl := &agentList{config: config}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists attested agents (all agents if no filters are given)",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.attestationType, "attestation-type", "", "", "Attestation type of the agents (e.g. join_token)")
+	cmd.Flags().VarP(&impl.banned, "banned", "", "Only list banned (or, with --banned=false, unbanned) agents")
+	cmd.Flags().Lookup("banned").NoOptDefVal = "true"
+	cmd.Flags().StringVarP(&impl.expiresBefore, "expires-before", "", "", "Only list agents whose SVID expires before an RFC3339 time or a duration from now (e.g. 24h)")
+	cmd.Flags().StringArrayVarP(&impl.selectors, "selector", "", nil, "Selector of the form TYPE:VALUE (repeatable)")
+	cmd.Flags().StringVarP(&impl.match, "match", "", "superset", "How selectors are matched (exact, subset, superset or any)")
+	cmd.Flags().BoolVarP(&impl.stale, "stale", "", false, "Only list agents whose SVID has expired")
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	return cmd
+}
+
+type agentList struct {
+	config          *rpcConfig
+	attestationType string
+	banned          optionalBool
+	expiresBefore   string
+	selectors       []string
+	match           string
+	stale           bool
+	output          string
+}
+
+func (cmd *agentList) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter, err := cmd.filter(now)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	agents, err := listAgents(ctx, agentv1.NewAgentClient(conn), filter)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.stale {
+		var stale []*types.Agent
+		for _, agent := range agents {
+			if isStale(agent, now) {
+				stale = append(stale, agent)
+			}
+		}
+		agents = stale
+	}
+	return formatAgents(cmd.output, agents, &agentv1.ListAgentsResponse{Agents: agents}, now)
+}
+
+func (cmd *agentList) filter(now time.Time) (*agentv1.ListAgentsRequest_Filter, error) {
+	filter := &agentv1.ListAgentsRequest_Filter{
+		ByAttestationType: cmd.attestationType,
+	}
+	if cmd.banned.set {
+		filter.ByBanned = wrapperspb.Bool(cmd.banned.value)
+	}
+	if cmd.expiresBefore != "" {
+		expiresBefore, err := parseTimeOrDuration(cmd.expiresBefore, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --expires-before: %v", err)
+		}
+		filter.ByExpiresBefore = expiresBefore.Format(expiresBeforeLayout)
+	}
+	if len(cmd.selectors) > 0 {
+		var err error
+		if filter.BySelectorMatch, err = parseSelectorMatch(cmd.selectors, cmd.match); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// parseTimeOrDuration parses an RFC3339 time or a duration relative to now.
+func parseTimeOrDuration(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC3339 time or a duration: %q", s)
+	}
+	return t, nil
+}
+
+// optionalBool is a boolean flag that records whether it was set.
+type optionalBool struct {
+	set   bool
+	value bool
+}
+
+func (b *optionalBool) Set(s string) error {
+	value, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	b.set = true
+	b.value = value
+	return nil
+}
+
+func (b *optionalBool) Type() string {
+	return "bool"
+}
+
+func (b *optionalBool) String() string {
+	if !b.set {
+		return ""
+	}
+	return strconv.FormatBool(b.value)
+}
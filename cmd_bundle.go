@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func BundleCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "bundle", Short: "Inspects SPIFFE bundles"}
+	cmd.AddCommand(BundleFetchCommand())
+	cmd.AddCommand(BundleDiffCommand())
+	return cmd
+}
+
+// formatBundle renders a bundle as a summary followed by tables of its
+// authorities or, when json is requested, as protojson.
+func formatBundle(format string, bundle *types.Bundle, now time.Time) ([]byte, error) {
+	if format == outputJSON {
+		return append(marshalProtoJSON(bundle), '\n'), nil
+	}
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, "Trust domain:    %s\n", bundle.TrustDomain)
+	fmt.Fprintf(out, "Sequence number: %d\n", bundle.SequenceNumber)
+	refreshHint := "none"
+	if bundle.RefreshHint != 0 {
+		refreshHint = (time.Duration(bundle.RefreshHint) * time.Second).String()
+	}
+	fmt.Fprintf(out, "Refresh hint:    %s\n", refreshHint)
+
+	fmt.Fprintf(out, "\nX.509 authorities (%d):\n", len(bundle.X509Authorities))
+	if len(bundle.X509Authorities) > 0 {
+		rows := make([][]string, 0, len(bundle.X509Authorities))
+		for _, authority := range bundle.X509Authorities {
+			cert, err := x509.ParseCertificate(authority.Asn1)
+			if err != nil {
+				return nil, fmt.Errorf("invalid X.509 authority: %v", err)
+			}
+			rows = append(rows, []string{
+				cert.Subject.String(),
+				cert.NotAfter.UTC().Format(time.RFC3339),
+				formatExpiresIn(cert.NotAfter, now),
+			})
+		}
+		if err := writeTable(out, []string{"SUBJECT", "NOT AFTER", "EXPIRES IN"}, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	fmt.Fprintf(out, "\nJWT authorities (%d):\n", len(bundle.JwtAuthorities))
+	if len(bundle.JwtAuthorities) > 0 {
+		rows := make([][]string, 0, len(bundle.JwtAuthorities))
+		for _, authority := range bundle.JwtAuthorities {
+			expiresAt, expiresIn := "never", ""
+			if authority.ExpiresAt != 0 {
+				expiresAt = time.Unix(authority.ExpiresAt, 0).UTC().Format(time.RFC3339)
+				expiresIn = formatExpiresIn(time.Unix(authority.ExpiresAt, 0), now)
+			}
+			rows = append(rows, []string{
+				authority.KeyId,
+				describePublicKey(authority.PublicKey),
+				expiresAt,
+				expiresIn,
+			})
+		}
+		if err := writeTable(out, []string{"KEY ID", "KEY TYPE", "EXPIRES AT", "EXPIRES IN"}, rows); err != nil {
+			return nil, err
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// describePublicKey describes the type of a PKIX encoded public key (e.g.
+// "EC P-256").
+func describePublicKey(der []byte) string {
+	publicKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return "invalid"
+	}
+	switch publicKey := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return "EC " + publicKey.Curve.Params().Name
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", publicKey.N.BitLen())
+	case ed25519.PublicKey:
+		return "Ed25519"
+	default:
+		return fmt.Sprintf("%T", publicKey)
+	}
+}
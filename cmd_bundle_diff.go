@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func BundleDiffCommand() *cobra.Command {
+	impl := &bundleDiff{}
+	cmd := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Reports the authorities added, removed or changed between two bundles",
+		Long: `Reports the authorities added, removed or changed between two bundles.
+
+Each bundle may be PEM, JWKS or protojson (as returned by the Bundle API).
+X.509 authorities are matched by subject key ID and subject, and JWT
+authorities by key ID.
+
+Tainted status is read from the bundles alone: it is only known for
+protojson bundles, whose tainted fields reflect authorities tainted via the
+LocalAuthority API at the time the bundle was fetched. The LocalAuthority API
+is not queried, so authorities tainted since then, or in PEM and JWKS
+bundles, are not reported as tainted. Fetch the bundle again (e.g. with
+"rpc bundle get-bundle") to diff the current tainted status.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runOut(impl),
+	}
+	return cmd
+}
+
+type bundleDiff struct{}
+
+// bundleAuthority is an authority of a bundle in a form suitable for
+// diffing.
+type bundleAuthority struct {
+	key         string
+	description string
+	tainted     bool
+}
+
+func (cmd *bundleDiff) Run(ctx context.Context, args []string) ([]byte, error) {
+	oldBundle, err := loadBundleFile(args[0])
+	if err != nil {
+		return nil, err
+	}
+	newBundle, err := loadBundleFile(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	oldX509, err := x509Authorities(oldBundle)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", args[0], err)
+	}
+	newX509, err := x509Authorities(newBundle)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", args[1], err)
+	}
+
+	out := new(bytes.Buffer)
+	if oldBundle.TrustDomain != newBundle.TrustDomain && oldBundle.TrustDomain != "" && newBundle.TrustDomain != "" {
+		fmt.Fprintf(out, "~ trust domain: %s -> %s\n", oldBundle.TrustDomain, newBundle.TrustDomain)
+	}
+	if oldBundle.SequenceNumber != newBundle.SequenceNumber {
+		fmt.Fprintf(out, "~ sequence number: %d -> %d\n", oldBundle.SequenceNumber, newBundle.SequenceNumber)
+	}
+	if oldBundle.RefreshHint != newBundle.RefreshHint {
+		fmt.Fprintf(out, "~ refresh hint: %ds -> %ds\n", oldBundle.RefreshHint, newBundle.RefreshHint)
+	}
+	x509Changes := diffAuthorities(out, "X.509 authority", oldX509, newX509)
+	jwtChanges := diffAuthorities(out, "JWT authority", jwtAuthorities(oldBundle), jwtAuthorities(newBundle))
+
+	if out.Len() == 0 {
+		return []byte("Bundles are identical\n"), nil
+	}
+	fmt.Fprintf(out, "X.509 authorities: %s\n", x509Changes)
+	fmt.Fprintf(out, "JWT authorities: %s\n", jwtChanges)
+	return out.Bytes(), nil
+}
+
+func loadBundleFile(path string) (*types.Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bundle: %v", err)
+	}
+	bundle, err := parseBundle(data, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return bundle, nil
+}
+
+func x509Authorities(bundle *types.Bundle) ([]bundleAuthority, error) {
+	authorities := make([]bundleAuthority, 0, len(bundle.X509Authorities))
+	for i, authority := range bundle.X509Authorities {
+		cert, err := x509.ParseCertificate(authority.Asn1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid X.509 authority %d: %v", i, err)
+		}
+		ski := formatKeyID(cert.SubjectKeyId)
+		if ski == "" {
+			// Fall back to the certificate fingerprint.
+			sum := sha256.Sum256(cert.Raw)
+			ski = "sha256:" + hex.EncodeToString(sum[:])
+		}
+		authorities = append(authorities, bundleAuthority{
+			key:         ski + " " + cert.Subject.String(),
+			description: fmt.Sprintf("SKI %s, subject %q, expires %s", ski, cert.Subject, cert.NotAfter.UTC().Format(time.RFC3339)),
+			tainted:     authority.Tainted,
+		})
+	}
+	return authorities, nil
+}
+
+func jwtAuthorities(bundle *types.Bundle) []bundleAuthority {
+	authorities := make([]bundleAuthority, 0, len(bundle.JwtAuthorities))
+	for _, authority := range bundle.JwtAuthorities {
+		authorities = append(authorities, bundleAuthority{
+			key:         authority.KeyId,
+			description: fmt.Sprintf("kid %q, %s", authority.KeyId, describePublicKey(authority.PublicKey)),
+			tainted:     authority.Tainted,
+		})
+	}
+	return authorities
+}
+
+// diffAuthorities writes a line for each authority that was added, removed
+// or whose tainted status changed, and returns a summary of the changes.
+func diffAuthorities(out *bytes.Buffer, kind string, oldAuthorities, newAuthorities []bundleAuthority) string {
+	oldByKey := make(map[string]bundleAuthority, len(oldAuthorities))
+	for _, authority := range oldAuthorities {
+		oldByKey[authority.key] = authority
+	}
+	newByKey := make(map[string]bundleAuthority, len(newAuthorities))
+	for _, authority := range newAuthorities {
+		newByKey[authority.key] = authority
+	}
+
+	var lines []string
+	added, removed, changed, unchanged := 0, 0, 0, 0
+	for _, authority := range oldAuthorities {
+		if _, ok := newByKey[authority.key]; !ok {
+			removed++
+			lines = append(lines, "- "+kind+" "+authority.description+taintedSuffix(authority.tainted))
+		}
+	}
+	for _, authority := range newAuthorities {
+		old, ok := oldByKey[authority.key]
+		switch {
+		case !ok:
+			added++
+			lines = append(lines, "+ "+kind+" "+authority.description+taintedSuffix(authority.tainted))
+		case old.tainted != authority.tainted:
+			changed++
+			lines = append(lines, fmt.Sprintf("~ %s %s: tainted %t -> %t", kind, authority.description, old.tainted, authority.tainted))
+		default:
+			unchanged++
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		// Removals first, then additions, then changes.
+		return strings.Index("-+~", lines[i][:1]) < strings.Index("-+~", lines[j][:1])
+	})
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+	return fmt.Sprintf("%d added, %d removed, %d changed, %d unchanged", added, removed, changed, unchanged)
+}
+
+func taintedSuffix(tainted bool) string {
+	if tainted {
+		return " (tainted)"
+	}
+	return ""
+}
+
+// formatKeyID formats a key ID as colon separated hex (e.g. 0a:1b:2c).
+func formatKeyID(id []byte) string {
+	parts := make([]string, 0, len(id))
+	for _, b := range id {
+		parts = append(parts, hex.EncodeToString([]byte{b}))
+	}
+	return strings.Join(parts, ":")
+}
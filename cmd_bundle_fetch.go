@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/federation"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+func BundleFetchCommand() *cobra.Command {
+	impl := &bundleFetch{}
+	cmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Fetches a bundle from a SPIFFE bundle endpoint",
+		Long: `Fetches a bundle from a SPIFFE bundle endpoint and prints its authorities.
+
+With the https_web profile the endpoint is authenticated using the system
+roots (or --ca-file). With the https_spiffe profile the endpoint must present
+an X509-SVID for --endpoint-id, verified against --endpoint-bundle (PEM, JWKS
+or protojson).`,
+		Args: cobra.NoArgs,
+		RunE: runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.url, "url", "", "", "URL of the bundle endpoint")
+	cmd.Flags().StringVarP(&impl.profile, "profile", "", profileHTTPSWeb, "Bundle endpoint profile (https_web or https_spiffe)")
+	cmd.Flags().StringVarP(&impl.endpointID, "endpoint-id", "", "", "SPIFFE ID of the bundle endpoint server (https_spiffe only)")
+	cmd.Flags().StringVarP(&impl.endpointBundle, "endpoint-bundle", "", "", "Bundle used to authenticate the bundle endpoint server (https_spiffe only)")
+	cmd.Flags().StringVarP(&impl.caFile, "ca-file", "", "", "PEM roots used to authenticate the bundle endpoint server instead of the system roots (https_web only)")
+	cmd.Flags().StringVarP(&impl.trustDomain, "trust-domain", "", "", "Trust domain of the bundle (defaults to the trust domain of --endpoint-id)")
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeChoices(profileHTTPSWeb, profileHTTPSSPIFFE))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	_ = cmd.MarkFlagRequired("url")
+	return cmd
+}
+
+type bundleFetch struct {
+	url            string
+	profile        string
+	endpointID     string
+	endpointBundle string
+	caFile         string
+	trustDomain    string
+	output         string
+}
+
+func (cmd *bundleFetch) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	var options []federation.FetchOption
+	var endpointID spiffeid.ID
+	switch cmd.profile {
+	case profileHTTPSWeb:
+		if cmd.endpointID != "" || cmd.endpointBundle != "" {
+			return nil, fmt.Errorf("--endpoint-id and --endpoint-bundle cannot be used with --profile %s", profileHTTPSWeb)
+		}
+		if cmd.caFile != "" {
+			roots, err := loadCertPool(cmd.caFile)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, federation.WithWebPKIRoots(roots))
+		}
+	case profileHTTPSSPIFFE:
+		if cmd.endpointID == "" || cmd.endpointBundle == "" {
+			return nil, fmt.Errorf("--endpoint-id and --endpoint-bundle are required with --profile %s", profileHTTPSSPIFFE)
+		}
+		if cmd.caFile != "" {
+			return nil, fmt.Errorf("--ca-file cannot be used with --profile %s", profileHTTPSSPIFFE)
+		}
+		var err error
+		endpointID, err = spiffeid.FromString(cmd.endpointID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint ID %q: %v", cmd.endpointID, err)
+		}
+		bundleSource, err := cmd.loadEndpointBundle(endpointID.TrustDomain())
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, federation.WithSPIFFEAuth(bundleSource, endpointID))
+	default:
+		return nil, fmt.Errorf("unknown profile %q (expected %s or %s)", cmd.profile, profileHTTPSWeb, profileHTTPSSPIFFE)
+	}
+
+	trustDomain := cmd.trustDomain
+	if trustDomain == "" {
+		if endpointID.IsZero() {
+			return nil, fmt.Errorf("--trust-domain is required with --profile %s", profileHTTPSWeb)
+		}
+		trustDomain = endpointID.TrustDomain().Name()
+	}
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain %q: %v", trustDomain, err)
+	}
+
+	spiffeBundle, err := federation.FetchBundle(ctx, td, cmd.url, options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch bundle: %v", err)
+	}
+	bundle, err := bundleFromSPIFFEBundle(spiffeBundle)
+	if err != nil {
+		return nil, err
+	}
+	return formatBundle(cmd.output, bundle, time.Now())
+}
+
+func (cmd *bundleFetch) loadEndpointBundle(td spiffeid.TrustDomain) (*x509bundle.Bundle, error) {
+	data, err := os.ReadFile(cmd.endpointBundle)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read endpoint bundle: %v", err)
+	}
+	bundle, err := parseBundle(data, td.Name())
+	if err != nil {
+		return nil, err
+	}
+	spiffeBundle, err := spiffeBundleFromBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+	return spiffeBundle.X509Bundle(), nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CA file: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("no certificates in CA file %q", path)
+	}
+	return pool, nil
+}
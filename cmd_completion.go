@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// maxFieldPathDepth bounds the nesting of request field paths offered
+	// for --set, since messages may be recursive.
+	maxFieldPathDepth = 4
+
+	// completionTimeout bounds fetching completions from the server, so
+	// that an unreachable server does not keep the shell waiting.
+	completionTimeout = time.Second
+)
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// registerBytesFormatCompletions registers completions for every
+// BytesFormatFlag in the command tree, offering the formats the flag
+// accepts.
+func registerBytesFormatCompletions(cmd *cobra.Command) {
+	register := func(flag *pflag.Flag) {
+		formats, ok := flag.Value.(*BytesFormatFlag)
+		if !ok {
+			return
+		}
+		names := make([]string, 0, len(*formats))
+		for _, format := range *formats {
+			names = append(names, format.Name())
+		}
+		sort.Strings(names)
+		_ = cmd.RegisterFlagCompletionFunc(flag.Name, completeChoices(names...))
+	}
+	cmd.Flags().VisitAll(register)
+	cmd.PersistentFlags().VisitAll(register)
+	for _, child := range cmd.Commands() {
+		registerBytesFormatCompletions(child)
+	}
+}
+
+// completeChoices completes a flag with fixed choices.
+func completeChoices(choices ...string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return choices, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFromServer completes a flag with values fetched from the server in
+// a single attempt bounded by completionTimeout. Failures (e.g. an
+// unreachable server) yield no completions.
+func completeFromServer(config *rpcConfig, fetch func(ctx context.Context, conn *grpc.ClientConn) ([]string, error)) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+		defer cancel()
+
+		conn, err := config.dial(ctx)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		defer config.closeSource()
+		defer conn.Close()
+
+		values, err := fetch(ctx, conn)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterCompletions(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeEntrySPIFFEIDs completes the SPIFFE IDs and parent IDs of the
+// registration entries.
+func completeEntrySPIFFEIDs(config *rpcConfig) completionFunc {
+	return completeFromServer(config, func(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+		entries, err := listEntries(ctx, entryv1.NewEntryClient(conn), nil)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, 2*len(entries))
+		for _, entry := range entries {
+			ids = append(ids, formatSPIFFEID(entry.SpiffeId), formatSPIFFEID(entry.ParentId))
+		}
+		return ids, nil
+	})
+}
+
+// completeEntryIDs completes the IDs of the registration entries.
+func completeEntryIDs(config *rpcConfig) completionFunc {
+	return completeFromServer(config, func(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+		entries, err := listEntries(ctx, entryv1.NewEntryClient(conn), nil)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			ids = append(ids, entry.Id)
+		}
+		return ids, nil
+	})
+}
+
+// completeAgentIDs completes the SPIFFE IDs of the attested agents.
+func completeAgentIDs(config *rpcConfig) completionFunc {
+	return completeFromServer(config, func(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+		agents, err := listAgents(ctx, agentv1.NewAgentClient(conn), nil)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(agents))
+		for _, agent := range agents {
+			ids = append(ids, formatSPIFFEID(agent.Id))
+		}
+		return ids, nil
+	})
+}
+
+// completeFederatedTrustDomains completes the trust domains of the
+// federation relationships.
+func completeFederatedTrustDomains(config *rpcConfig) completionFunc {
+	return completeFromServer(config, func(ctx context.Context, conn *grpc.ClientConn) ([]string, error) {
+		relationships, err := listFederationRelationships(ctx, trustdomainv1.NewTrustDomainClient(conn))
+		if err != nil {
+			return nil, err
+		}
+		trustDomains := make([]string, 0, len(relationships))
+		for _, relationship := range relationships {
+			trustDomains = append(trustDomains, relationship.TrustDomain)
+		}
+		return trustDomains, nil
+	})
+}
+
+// completeRequestFieldPaths completes the field paths of a request message
+// for --set, e.g. entries[0].spiffe_id.trust_domain=.
+func completeRequestFieldPaths(newRequest func() proto.Message) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if strings.Contains(toComplete, "=") {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var paths []string
+		appendFieldPaths(&paths, newRequest().ProtoReflect().Descriptor(), "", 0)
+		return filterCompletions(paths, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+func appendFieldPaths(paths *[]string, md protoreflect.MessageDescriptor, prefix string, depth int) {
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := prefix + string(fd.Name())
+		if fd.IsList() {
+			path += "[0]"
+		}
+		*paths = append(*paths, path+"=")
+		if fd.Message() != nil && !fd.IsMap() && depth+1 < maxFieldPathDepth &&
+			!strings.HasPrefix(string(fd.Message().FullName()), "google.protobuf.") {
+			appendFieldPaths(paths, fd.Message(), path+".", depth+1)
+		}
+	}
+}
+
+// filterCompletions returns the unique, non-empty values with the given
+// prefix, sorted.
+func filterCompletions(values []string, prefix string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, value := range values {
+		if value != "" && strings.HasPrefix(value, prefix) && !seen[value] {
+			seen[value] = true
+			out = append(out, value)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterCompletions(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		values []string
+		prefix string
+		want   []string
+	}{
+		{
+			name:   "no values",
+			prefix: "spiffe://",
+		},
+		{
+			name:   "sorted",
+			values: []string{"spiffe://example.org/b", "spiffe://example.org/a"},
+			want:   []string{"spiffe://example.org/a", "spiffe://example.org/b"},
+		},
+		{
+			name:   "prefix",
+			values: []string{"spiffe://example.org/a", "spiffe://other.test/a"},
+			prefix: "spiffe://example",
+			want:   []string{"spiffe://example.org/a"},
+		},
+		{
+			name:   "duplicates and empty values",
+			values: []string{"spiffe://example.org/a", "", "spiffe://example.org/a"},
+			want:   []string{"spiffe://example.org/a"},
+		},
+		{
+			name:   "no match",
+			values: []string{"spiffe://example.org/a"},
+			prefix: "x",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterCompletions(tt.values, tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -35,7 +35,18 @@ func (cmd *dumpJWTSVIDID) Run(_ context.Context, in []byte, args []string) ([]by
 		return nil, fmt.Errorf("JWT-SVID has invalid format: %v", err)
 	}
 
-	tok, err := jwt.ParseSigned(string(svidBytes), []jose.SignatureAlgorithm{
+	claims, err := parseJWTSVIDClaims(string(svidBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(claims.Subject + "\n"), nil
+}
+
+// parseJWTSVIDClaims returns the claims of a JWT-SVID without verifying its
+// signature.
+func parseJWTSVIDClaims(token string) (*jwt.Claims, error) {
+	tok, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{
 		jose.RS256, jose.RS384, jose.RS512,
 		jose.ES256, jose.ES384, jose.ES512,
 		jose.PS256, jose.PS384, jose.PS512,
@@ -44,14 +55,13 @@ func (cmd *dumpJWTSVIDID) Run(_ context.Context, in []byte, args []string) ([]by
 		return nil, fmt.Errorf("unable to parse JWT-SIVD: %v", err)
 	}
 
-	var claims jwt.Claims
-	if err := tok.UnsafeClaimsWithoutVerification(&claims); err != nil {
+	claims := new(jwt.Claims)
+	if err := tok.UnsafeClaimsWithoutVerification(claims); err != nil {
 		return nil, fmt.Errorf("unable to get claims from JWT-SVID: %v", err)
 	}
 
 	if len(claims.Subject) == 0 {
 		return nil, errors.New("JWT-SVID missing SPIFFE ID claim")
 	}
-
-	return []byte(claims.Subject + "\n"), nil
+	return claims, nil
 }
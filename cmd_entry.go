@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func EntryCommand() *cobra.Command {
+	config := new(rpcConfig)
+	cmd := &cobra.Command{Use: "entry", Short: "Manages registration entries via the Entry API"}
+	addServerFlags(cmd.PersistentFlags(), config)
+	addConnectionFlags(cmd.PersistentFlags(), config)
+	cmd.PersistentFlags().DurationVarP(&config.timeout, "timeout", "", time.Minute, "Timeout for the command, including any paging")
+	cmd.AddCommand(EntryCreateCommand(config))
+	cmd.AddCommand(EntryShowCommand(config))
+	cmd.AddCommand(EntryDeleteCommand(config))
+	cmd.AddCommand(EntryFindCommand(config))
+	cmd.AddCommand(EntryApplyCommand(config))
+	return cmd
+}
+
+func parseSPIFFEID(s string) (*types.SPIFFEID, error) {
+	id, err := spiffeid.FromString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: %v", s, err)
+	}
+	return &types.SPIFFEID{TrustDomain: id.TrustDomain().Name(), Path: id.Path()}, nil
+}
+
+func formatSPIFFEID(id *types.SPIFFEID) string {
+	if id == nil {
+		return ""
+	}
+	return "spiffe://" + id.TrustDomain + id.Path
+}
+
+// parseSelector parses a selector of the form TYPE:VALUE (e.g. k8s:ns:foo).
+func parseSelector(s string) (*types.Selector, error) {
+	selectorType, value, ok := strings.Cut(s, ":")
+	if !ok || selectorType == "" || value == "" {
+		return nil, fmt.Errorf("invalid selector %q: expected TYPE:VALUE", s)
+	}
+	return &types.Selector{Type: selectorType, Value: value}, nil
+}
+
+func parseSelectors(ss []string) ([]*types.Selector, error) {
+	selectors := make([]*types.Selector, 0, len(ss))
+	for _, s := range ss {
+		selector, err := parseSelector(s)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// parseSelectorMatch parses selectors and a match behavior (exact, subset,
+// superset or any).
+func parseSelectorMatch(ss []string, match string) (*types.SelectorMatch, error) {
+	selectors, err := parseSelectors(ss)
+	if err != nil {
+		return nil, err
+	}
+	behavior, ok := types.SelectorMatch_MatchBehavior_value["MATCH_"+strings.ToUpper(match)]
+	if !ok {
+		return nil, fmt.Errorf("unknown match behavior %q", match)
+	}
+	return &types.SelectorMatch{
+		Selectors: selectors,
+		Match:     types.SelectorMatch_MatchBehavior(behavior),
+	}, nil
+}
+
+func formatSelectors(selectors []*types.Selector) string {
+	ss := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		ss = append(ss, selector.Type+":"+selector.Value)
+	}
+	return strings.Join(ss, ",")
+}
+
+// formatEntries renders entries as a table or, when json is requested, as
+// the given message (which carries the entries).
+func formatEntries(format string, entries []*types.Entry, m proto.Message) ([]byte, error) {
+	if format == outputJSON {
+		return append(marshalProtoJSON(m), '\n'), nil
+	}
+
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			entry.Id,
+			formatSPIFFEID(entry.SpiffeId),
+			formatSPIFFEID(entry.ParentId),
+			formatSelectors(entry.Selectors),
+			strconv.Itoa(int(entry.X509SvidTtl)),
+		})
+	}
+	out := new(bytes.Buffer)
+	if err := writeTable(out, []string{"ENTRY ID", "SPIFFE ID", "PARENT ID", "SELECTORS", "X509-SVID TTL"}, rows); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
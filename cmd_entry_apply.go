@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+func EntryApplyCommand(config *rpcConfig) *cobra.Command {
+	impl := &entryApply{config: config}
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Reconciles registration entries with a declarative YAML (or JSON) file",
+		Long: `Reconciles registration entries with a declarative YAML (or JSON) file, e.g.:
+
+  entries:
+  - spiffe_id: spiffe://example.org/web
+    parent_id: spiffe://example.org/node
+    selectors: ["k8s:ns:web", "k8s:sa:web"]
+    x509_svid_ttl: 1h
+    dns_names: [web.example.org]
+
+Entries are matched with existing entries by SPIFFE ID, parent ID and
+selectors. Matching entries whose other fields differ are updated, and
+missing entries are created. Existing entries that are not in the file are
+only deleted when --prune is given. TTLs that are unset (or zero) are left to
+the server default and not compared.`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			impl.stdin = cobraCmd.InOrStdin()
+			return runOut(impl)(cobraCmd, args)
+		},
+	}
+	cmd.Flags().StringVarP(&impl.file, "file", "f", "", "File containing the desired entries (- for stdin)")
+	cmd.Flags().BoolVarP(&impl.dryRun, "dry-run", "", false, "Only show the plan")
+	cmd.Flags().BoolVarP(&impl.prune, "prune", "", false, "Delete entries that are not in the file")
+	_ = cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+type entryApply struct {
+	config *rpcConfig
+	file   string
+	dryRun bool
+	prune  bool
+	stdin  io.Reader
+}
+
+// entrySpec is the declarative form of a registration entry.
+type entrySpec struct {
+	SPIFFEID      string       `json:"spiffe_id"`
+	ParentID      string       `json:"parent_id"`
+	Selectors     []string     `json:"selectors"`
+	X509SVIDTTL   specDuration `json:"x509_svid_ttl"`
+	JWTSVIDTTL    specDuration `json:"jwt_svid_ttl"`
+	DNSNames      []string     `json:"dns_names"`
+	FederatesWith []string     `json:"federates_with"`
+	Admin         bool         `json:"admin"`
+	Downstream    bool         `json:"downstream"`
+	StoreSVID     bool         `json:"store_svid"`
+	Hint          string       `json:"hint"`
+}
+
+// specDuration is a duration given either as a string (e.g. "1h") or as a
+// number of seconds.
+type specDuration time.Duration
+
+func (d *specDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = specDuration(parsed)
+		return nil
+	}
+	var seconds int64
+	if err := json.Unmarshal(b, &seconds); err != nil {
+		return fmt.Errorf("invalid duration %s", b)
+	}
+	if seconds > math.MaxInt64/int64(time.Second) || seconds < math.MinInt64/int64(time.Second) {
+		return fmt.Errorf("invalid duration %s: out of range", b)
+	}
+	*d = specDuration(time.Duration(seconds) * time.Second)
+	return nil
+}
+
+type entryPlan struct {
+	create []*types.Entry
+	update []*types.Entry
+	delete []*types.Entry
+	lines  []string
+}
+
+func (cmd *entryApply) Run(ctx context.Context, args []string) ([]byte, error) {
+	desired, err := cmd.loadDesired()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	client := entryv1.NewEntryClient(conn)
+
+	current, err := listEntries(ctx, client, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planEntries(desired, current, cmd.prune)
+
+	out := new(bytes.Buffer)
+	for _, line := range plan.lines {
+		fmt.Fprintln(out, line)
+	}
+	fmt.Fprintf(out, "Plan: %d to create, %d to update, %d to delete.\n", len(plan.create), len(plan.update), len(plan.delete))
+	if cmd.dryRun || len(plan.lines) == 0 {
+		return out.Bytes(), nil
+	}
+
+	var errs []string
+	if len(plan.create) > 0 {
+		resp, err := client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: plan.create})
+		if err == nil {
+			err = checkBatchResults(out, "BatchCreateEntry", resp)
+		} else {
+			err = newRPCError("BatchCreateEntry", err)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(plan.update) > 0 {
+		resp, err := client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{Entries: plan.update})
+		if err == nil {
+			err = checkBatchResults(out, "BatchUpdateEntry", resp)
+		} else {
+			err = newRPCError("BatchUpdateEntry", err)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(plan.delete) > 0 {
+		ids := make([]string, 0, len(plan.delete))
+		for _, entry := range plan.delete {
+			ids = append(ids, entry.Id)
+		}
+		resp, err := client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: ids})
+		if err == nil {
+			err = checkBatchResults(out, "BatchDeleteEntry", resp)
+		} else {
+			err = newRPCError("BatchDeleteEntry", err)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return out.Bytes(), fmt.Errorf("apply failed: %s", strings.Join(errs, "; "))
+	}
+	return out.Bytes(), nil
+}
+
+func (cmd *entryApply) loadDesired() ([]*types.Entry, error) {
+	var data []byte
+	var err error
+	if cmd.file == "-" {
+		data, err = io.ReadAll(cmd.stdin)
+	} else {
+		data, err = os.ReadFile(cmd.file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read entries: %v", err)
+	}
+
+	var doc struct {
+		Entries []entrySpec `json:"entries"`
+	}
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse entries: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	entries := make([]*types.Entry, 0, len(doc.Entries))
+	for i, spec := range doc.Entries {
+		entry, err := spec.entry()
+		if err != nil {
+			if spec.SPIFFEID != "" {
+				return nil, fmt.Errorf("entries[%d] (%s): %v", i, spec.SPIFFEID, err)
+			}
+			return nil, fmt.Errorf("entries[%d]: %v", i, err)
+		}
+		key := entryKey(entry)
+		if seen[key] {
+			return nil, fmt.Errorf("entries[%d]: duplicate entry for %s", i, describeEntry(entry))
+		}
+		seen[key] = true
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (spec entrySpec) entry() (*types.Entry, error) {
+	spiffeID, err := parseSPIFFEID(spec.SPIFFEID)
+	if err != nil {
+		return nil, err
+	}
+	parentID, err := parseSPIFFEID(spec.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Selectors) == 0 {
+		return nil, fmt.Errorf("at least one selector is required")
+	}
+	selectors, err := parseSelectors(spec.Selectors)
+	if err != nil {
+		return nil, err
+	}
+	x509SVIDTTL, err := ttlSeconds(time.Duration(spec.X509SVIDTTL))
+	if err != nil {
+		return nil, fmt.Errorf("x509_svid_ttl: %v", err)
+	}
+	jwtSVIDTTL, err := ttlSeconds(time.Duration(spec.JWTSVIDTTL))
+	if err != nil {
+		return nil, fmt.Errorf("jwt_svid_ttl: %v", err)
+	}
+	return &types.Entry{
+		SpiffeId:      spiffeID,
+		ParentId:      parentID,
+		Selectors:     selectors,
+		X509SvidTtl:   x509SVIDTTL,
+		JwtSvidTtl:    jwtSVIDTTL,
+		DnsNames:      spec.DNSNames,
+		FederatesWith: spec.FederatesWith,
+		Admin:         spec.Admin,
+		Downstream:    spec.Downstream,
+		StoreSvid:     spec.StoreSVID,
+		Hint:          spec.Hint,
+	}, nil
+}
+
+// planEntries computes the changes needed to reconcile the current entries
+// with the desired entries.
+func planEntries(desired, current []*types.Entry, prune bool) *entryPlan {
+	plan := new(entryPlan)
+
+	currentByKey := make(map[string]*types.Entry, len(current))
+	for _, entry := range current {
+		currentByKey[entryKey(entry)] = entry
+	}
+
+	desiredKeys := make(map[string]bool, len(desired))
+	for _, want := range desired {
+		key := entryKey(want)
+		desiredKeys[key] = true
+
+		have, ok := currentByKey[key]
+		if !ok {
+			plan.create = append(plan.create, want)
+			plan.lines = append(plan.lines, "+ create "+describeEntry(want))
+			continue
+		}
+		if changed := changedEntryFields(want, have); len(changed) > 0 {
+			update := &types.Entry{
+				Id:            have.Id,
+				SpiffeId:      want.SpiffeId,
+				ParentId:      want.ParentId,
+				Selectors:     want.Selectors,
+				X509SvidTtl:   want.X509SvidTtl,
+				JwtSvidTtl:    want.JwtSvidTtl,
+				DnsNames:      want.DnsNames,
+				FederatesWith: want.FederatesWith,
+				Admin:         want.Admin,
+				Downstream:    want.Downstream,
+				StoreSvid:     want.StoreSvid,
+				Hint:          want.Hint,
+				ExpiresAt:     have.ExpiresAt,
+			}
+			// Unset TTLs are left to the server default; keep what the
+			// server already has rather than resetting them.
+			if update.X509SvidTtl == 0 {
+				update.X509SvidTtl = have.X509SvidTtl
+			}
+			if update.JwtSvidTtl == 0 {
+				update.JwtSvidTtl = have.JwtSvidTtl
+			}
+			plan.update = append(plan.update, update)
+			plan.lines = append(plan.lines, fmt.Sprintf("~ update %s %s (%s)", have.Id, describeEntry(want), strings.Join(changed, ", ")))
+		}
+	}
+
+	if prune {
+		for _, have := range current {
+			if !desiredKeys[entryKey(have)] {
+				plan.delete = append(plan.delete, have)
+				plan.lines = append(plan.lines, fmt.Sprintf("- delete %s %s", have.Id, describeEntry(have)))
+			}
+		}
+	}
+	return plan
+}
+
+// changedEntryFields returns the names of the fields that differ between the
+// desired and current entry.
+func changedEntryFields(want, have *types.Entry) []string {
+	var changed []string
+	if want.X509SvidTtl != 0 && want.X509SvidTtl != have.X509SvidTtl {
+		changed = append(changed, "x509_svid_ttl")
+	}
+	if want.JwtSvidTtl != 0 && want.JwtSvidTtl != have.JwtSvidTtl {
+		changed = append(changed, "jwt_svid_ttl")
+	}
+	if !sameStrings(want.DnsNames, have.DnsNames) {
+		changed = append(changed, "dns_names")
+	}
+	if !sameStrings(want.FederatesWith, have.FederatesWith) {
+		changed = append(changed, "federates_with")
+	}
+	if want.Admin != have.Admin {
+		changed = append(changed, "admin")
+	}
+	if want.Downstream != have.Downstream {
+		changed = append(changed, "downstream")
+	}
+	if want.StoreSvid != have.StoreSvid {
+		changed = append(changed, "store_svid")
+	}
+	if want.Hint != have.Hint {
+		changed = append(changed, "hint")
+	}
+	return changed
+}
+
+// entryKey identifies an entry by SPIFFE ID, parent ID and selectors, in any
+// order. The parts are joined with NUL, which cannot appear in SPIFFE IDs
+// and is not used in selectors, whose values may contain commas.
+func entryKey(entry *types.Entry) string {
+	parts := make([]string, 0, len(entry.Selectors))
+	for _, selector := range entry.Selectors {
+		parts = append(parts, selector.Type+":"+selector.Value)
+	}
+	sort.Strings(parts)
+	parts = append([]string{formatSPIFFEID(entry.SpiffeId), formatSPIFFEID(entry.ParentId)}, parts...)
+	return strings.Join(parts, "\x00")
+}
+
+func describeEntry(entry *types.Entry) string {
+	return fmt.Sprintf("%s (parent %s, selectors %s)", formatSPIFFEID(entry.SpiffeId), formatSPIFFEID(entry.ParentId), formatSelectors(entry.Selectors))
+}
+
+// sameStrings returns true if a and b contain the same strings, in any
+// order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func TestEntryKey(t *testing.T) {
+	spiffeID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"}
+	parentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"}
+	entry := func(selectors ...*types.Selector) *types.Entry {
+		return &types.Entry{SpiffeId: spiffeID, ParentId: parentID, Selectors: selectors}
+	}
+	uid := &types.Selector{Type: "unix", Value: "uid:1000"}
+	gid := &types.Selector{Type: "unix", Value: "gid:1000"}
+
+	for _, tt := range []struct {
+		name string
+		a, b *types.Entry
+		same bool
+	}{
+		{
+			name: "same selectors",
+			a:    entry(uid, gid),
+			b:    entry(uid, gid),
+			same: true,
+		},
+		{
+			name: "selector order",
+			a:    entry(uid, gid),
+			b:    entry(gid, uid),
+			same: true,
+		},
+		{
+			name: "different selectors",
+			a:    entry(uid),
+			b:    entry(gid),
+		},
+		{
+			name: "selector value with comma",
+			a:    entry(&types.Selector{Type: "k8s", Value: "pod-label:a,k8s:ns:b"}),
+			b:    entry(&types.Selector{Type: "k8s", Value: "pod-label:a"}, &types.Selector{Type: "k8s", Value: "ns:b"}),
+		},
+		{
+			name: "different SPIFFE ID",
+			a:    entry(uid),
+			b:    &types.Entry{SpiffeId: parentID, ParentId: parentID, Selectors: []*types.Selector{uid}},
+		},
+		{
+			name: "different parent ID",
+			a:    entry(uid),
+			b:    &types.Entry{SpiffeId: spiffeID, ParentId: spiffeID, Selectors: []*types.Selector{uid}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := entryKey(tt.a) == entryKey(tt.b); same != tt.same {
+				t.Fatalf("expected same key to be %t; got %t", tt.same, same)
+			}
+		})
+	}
+}
+
+func TestEntryApplyLoadDesired(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		doc         string
+		wantX509TTL int32
+		wantJWTTTL  int32
+		wantErr     string
+	}{
+		{
+			name: "server default TTLs",
+			doc:  `{spiffe_id: "spiffe://example.org/web", parent_id: "spiffe://example.org/node", selectors: ["unix:uid:1000"]}`,
+		},
+		{
+			name:        "TTLs as durations and seconds",
+			doc:         `{spiffe_id: "spiffe://example.org/web", parent_id: "spiffe://example.org/node", selectors: ["unix:uid:1000"], x509_svid_ttl: 1h, jwt_svid_ttl: 300}`,
+			wantX509TTL: 3600,
+			wantJWTTTL:  300,
+		},
+		{
+			name:    "fractional TTL",
+			doc:     `{spiffe_id: "spiffe://example.org/web", parent_id: "spiffe://example.org/node", selectors: ["unix:uid:1000"], x509_svid_ttl: 1.5s}`,
+			wantErr: "entries[0] (spiffe://example.org/web): x509_svid_ttl: invalid TTL 1.5s: must be a whole number of seconds",
+		},
+		{
+			name:    "negative TTL",
+			doc:     `{spiffe_id: "spiffe://example.org/web", parent_id: "spiffe://example.org/node", selectors: ["unix:uid:1000"], jwt_svid_ttl: -60}`,
+			wantErr: "entries[0] (spiffe://example.org/web): jwt_svid_ttl: invalid TTL -1m0s: must not be negative",
+		},
+		{
+			name:    "TTL too large",
+			doc:     `{spiffe_id: "spiffe://example.org/web", parent_id: "spiffe://example.org/node", selectors: ["unix:uid:1000"], x509_svid_ttl: 2147483648}`,
+			wantErr: "entries[0] (spiffe://example.org/web): x509_svid_ttl: invalid TTL 596523h14m8s: must be at most",
+		},
+		{
+			name:    "TTL out of duration range",
+			doc:     `{spiffe_id: "spiffe://example.org/web", parent_id: "spiffe://example.org/node", selectors: ["unix:uid:1000"], x509_svid_ttl: 9223372036854775807}`,
+			wantErr: "out of range",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &entryApply{file: "-", stdin: strings.NewReader("entries:\n- " + tt.doc + "\n")}
+			entries, err := cmd.loadDesired()
+			assertErrorContains(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+			if entries[0].X509SvidTtl != tt.wantX509TTL {
+				t.Fatalf("expected X509-SVID TTL %d; got %d", tt.wantX509TTL, entries[0].X509SvidTtl)
+			}
+			if entries[0].JwtSvidTtl != tt.wantJWTTTL {
+				t.Fatalf("expected JWT-SVID TTL %d; got %d", tt.wantJWTTTL, entries[0].JwtSvidTtl)
+			}
+		})
+	}
+}
+
+func TestPlanEntries(t *testing.T) {
+	parentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/node"}
+	entry := func(id, path string, x509SVIDTTL int32, dnsNames ...string) *types.Entry {
+		return &types.Entry{
+			Id:          id,
+			SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: path},
+			ParentId:    parentID,
+			Selectors:   []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+			X509SvidTtl: x509SVIDTTL,
+			DnsNames:    dnsNames,
+		}
+	}
+
+	for _, tt := range []struct {
+		name       string
+		desired    []*types.Entry
+		current    []*types.Entry
+		prune      bool
+		wantCreate []string
+		wantUpdate []string
+		wantDelete []string
+		wantLines  []string
+	}{
+		{
+			name:       "create",
+			desired:    []*types.Entry{entry("", "/web", 0)},
+			wantCreate: []string{"/web"},
+			wantLines:  []string{"+ create spiffe://example.org/web"},
+		},
+		{
+			name:       "update",
+			desired:    []*types.Entry{entry("", "/web", 0, "web.example.org")},
+			current:    []*types.Entry{entry("ID1", "/web", 3600)},
+			wantUpdate: []string{"ID1"},
+			wantLines:  []string{"~ update ID1 spiffe://example.org/web"},
+		},
+		{
+			name:    "unchanged",
+			desired: []*types.Entry{entry("", "/web", 3600, "web.example.org")},
+			current: []*types.Entry{entry("ID1", "/web", 3600, "web.example.org")},
+		},
+		{
+			name:    "unset TTL is not compared",
+			desired: []*types.Entry{entry("", "/web", 0)},
+			current: []*types.Entry{entry("ID1", "/web", 3600)},
+		},
+		{
+			name:       "delete",
+			desired:    []*types.Entry{entry("", "/web", 0)},
+			current:    []*types.Entry{entry("ID1", "/web", 0), entry("ID2", "/db", 0)},
+			prune:      true,
+			wantDelete: []string{"ID2"},
+			wantLines:  []string{"- delete ID2 spiffe://example.org/db"},
+		},
+		{
+			name:    "prune off",
+			desired: []*types.Entry{entry("", "/web", 0)},
+			current: []*types.Entry{entry("ID1", "/web", 0), entry("ID2", "/db", 0)},
+		},
+		{
+			name:       "create, update and delete",
+			desired:    []*types.Entry{entry("", "/web", 0), entry("", "/api", 60)},
+			current:    []*types.Entry{entry("ID1", "/api", 3600), entry("ID2", "/db", 0)},
+			prune:      true,
+			wantCreate: []string{"/web"},
+			wantUpdate: []string{"ID1"},
+			wantDelete: []string{"ID2"},
+			wantLines: []string{
+				"+ create spiffe://example.org/web",
+				"~ update ID1 spiffe://example.org/api",
+				"- delete ID2 spiffe://example.org/db",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planEntries(tt.desired, tt.current, tt.prune)
+
+			var create, update, del []string
+			for _, entry := range plan.create {
+				create = append(create, entry.SpiffeId.Path)
+			}
+			for _, entry := range plan.update {
+				update = append(update, entry.Id)
+			}
+			for _, entry := range plan.delete {
+				del = append(del, entry.Id)
+			}
+			if !slices.Equal(create, tt.wantCreate) {
+				t.Fatalf("expected to create %q; got %q", tt.wantCreate, create)
+			}
+			if !slices.Equal(update, tt.wantUpdate) {
+				t.Fatalf("expected to update %q; got %q", tt.wantUpdate, update)
+			}
+			if !slices.Equal(del, tt.wantDelete) {
+				t.Fatalf("expected to delete %q; got %q", tt.wantDelete, del)
+			}
+			if len(plan.lines) != len(tt.wantLines) {
+				t.Fatalf("expected %d plan lines; got %q", len(tt.wantLines), plan.lines)
+			}
+			for i, want := range tt.wantLines {
+				if !strings.HasPrefix(plan.lines[i], want) {
+					t.Fatalf("expected plan line %d to start with %q; got %q", i, want, plan.lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPlanEntriesKeepsServerTTLs(t *testing.T) {
+	spiffeID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/web"}
+	parentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/node"}
+	selectors := []*types.Selector{{Type: "unix", Value: "uid:1000"}}
+	desired := &types.Entry{SpiffeId: spiffeID, ParentId: parentID, Selectors: selectors, Admin: true}
+	current := &types.Entry{Id: "ID1", SpiffeId: spiffeID, ParentId: parentID, Selectors: selectors, X509SvidTtl: 3600, JwtSvidTtl: 300}
+
+	plan := planEntries([]*types.Entry{desired}, []*types.Entry{current}, false)
+	if len(plan.update) != 1 {
+		t.Fatalf("expected one update; got %d", len(plan.update))
+	}
+	if update := plan.update[0]; update.X509SvidTtl != 3600 || update.JwtSvidTtl != 300 || !update.Admin {
+		t.Fatalf("unexpected update: %v", update)
+	}
+}
+
+func TestChangedEntryFields(t *testing.T) {
+	base := func() *types.Entry {
+		return &types.Entry{
+			X509SvidTtl:   3600,
+			JwtSvidTtl:    300,
+			DnsNames:      []string{"a.example.org", "b.example.org"},
+			FederatesWith: []string{"other.org"},
+			Hint:          "web",
+		}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		modify func(want *types.Entry)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(want *types.Entry) {},
+		},
+		{
+			name: "DNS names in another order",
+			modify: func(want *types.Entry) {
+				want.DnsNames = []string{"b.example.org", "a.example.org"}
+			},
+		},
+		{
+			name: "unset TTLs",
+			modify: func(want *types.Entry) {
+				want.X509SvidTtl = 0
+				want.JwtSvidTtl = 0
+			},
+		},
+		{
+			name: "TTLs",
+			modify: func(want *types.Entry) {
+				want.X509SvidTtl = 60
+				want.JwtSvidTtl = 60
+			},
+			want: []string{"x509_svid_ttl", "jwt_svid_ttl"},
+		},
+		{
+			name: "lists",
+			modify: func(want *types.Entry) {
+				want.DnsNames = []string{"a.example.org"}
+				want.FederatesWith = nil
+			},
+			want: []string{"dns_names", "federates_with"},
+		},
+		{
+			name: "flags and hint",
+			modify: func(want *types.Entry) {
+				want.Admin = true
+				want.Downstream = true
+				want.StoreSvid = true
+				want.Hint = ""
+			},
+			want: []string{"admin", "downstream", "store_svid", "hint"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			want := base()
+			tt.modify(want)
+			if got := changedEntryFields(want, base()); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
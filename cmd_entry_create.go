@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func EntryCreateCommand(config *rpcConfig) *cobra.Command {
+	impl := &entryCreate{config: config}
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Creates a registration entry",
+		Args:  cobra.NoArgs,
+		RunE:  runOutErr(impl),
+	}
+	cmd.Flags().StringVarP(&impl.spiffeID, "spiffe-id", "", "", "SPIFFE ID of the entry")
+	cmd.Flags().StringVarP(&impl.parentID, "parent-id", "", "", "SPIFFE ID of the parent of the entry")
+	cmd.Flags().BoolVarP(&impl.node, "node", "", false, "Create a node entry (parented by the SPIRE Server)")
+	cmd.Flags().StringArrayVarP(&impl.selectors, "selector", "", nil, "Selector of the form TYPE:VALUE (repeatable)")
+	cmd.Flags().DurationVarP(&impl.x509SVIDTTL, "ttl", "", 0, "TTL of X509-SVIDs issued for the entry (server default if zero)")
+	cmd.Flags().DurationVarP(&impl.jwtSVIDTTL, "jwt-ttl", "", 0, "TTL of JWT-SVIDs issued for the entry (server default if zero)")
+	cmd.Flags().StringArrayVarP(&impl.dnsNames, "dns-name", "", nil, "DNS name to include in X509-SVIDs (repeatable)")
+	cmd.Flags().StringArrayVarP(&impl.federatesWith, "federates-with", "", nil, "Trust domain the entry federates with (repeatable)")
+	cmd.Flags().BoolVarP(&impl.admin, "admin", "", false, "Grant admin privileges to the entry")
+	cmd.Flags().BoolVarP(&impl.downstream, "downstream", "", false, "Mark the entry as a downstream SPIRE Server")
+	cmd.Flags().StringVarP(&impl.hint, "hint", "", "", "Hint for workloads with more than one SVID")
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("spiffe-id", completeEntrySPIFFEIDs(config))
+	_ = cmd.RegisterFlagCompletionFunc("parent-id", completeEntrySPIFFEIDs(config))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	_ = cmd.MarkFlagRequired("spiffe-id")
+	_ = cmd.MarkFlagRequired("selector")
+	return cmd
+}
+
+type entryCreate struct {
+	config        *rpcConfig
+	spiffeID      string
+	parentID      string
+	node          bool
+	selectors     []string
+	x509SVIDTTL   time.Duration
+	jwtSVIDTTL    time.Duration
+	dnsNames      []string
+	federatesWith []string
+	admin         bool
+	downstream    bool
+	hint          string
+	output        string
+}
+
+func (cmd *entryCreate) Run(ctx context.Context, stderr io.Writer, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	entry, err := cmd.entry()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := entryv1.NewEntryClient(conn).BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+		Entries: []*types.Entry{entry},
+	})
+	if err != nil {
+		return nil, newRPCError("BatchCreateEntry", err)
+	}
+	status := new(bytes.Buffer)
+	if err := checkBatchResults(status, "BatchCreateEntry", resp); err != nil {
+		stderr.Write(status.Bytes())
+		return nil, err
+	}
+
+	created := resp.Results[0].Entry
+	return formatEntries(cmd.output, []*types.Entry{created}, created)
+}
+
+func (cmd *entryCreate) entry() (*types.Entry, error) {
+	spiffeID, err := parseSPIFFEID(cmd.spiffeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentID *types.SPIFFEID
+	switch {
+	case cmd.node && cmd.parentID != "":
+		return nil, fmt.Errorf("--node and --parent-id are mutually exclusive")
+	case cmd.node:
+		parentID = &types.SPIFFEID{TrustDomain: spiffeID.TrustDomain, Path: "/spire/server"}
+	case cmd.parentID != "":
+		if parentID, err = parseSPIFFEID(cmd.parentID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("one of --parent-id or --node is required")
+	}
+
+	selectors, err := parseSelectors(cmd.selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	x509SVIDTTL, err := ttlSeconds(cmd.x509SVIDTTL)
+	if err != nil {
+		return nil, fmt.Errorf("--ttl: %v", err)
+	}
+	jwtSVIDTTL, err := ttlSeconds(cmd.jwtSVIDTTL)
+	if err != nil {
+		return nil, fmt.Errorf("--jwt-ttl: %v", err)
+	}
+
+	return &types.Entry{
+		SpiffeId:      spiffeID,
+		ParentId:      parentID,
+		Selectors:     selectors,
+		X509SvidTtl:   x509SVIDTTL,
+		JwtSvidTtl:    jwtSVIDTTL,
+		DnsNames:      cmd.dnsNames,
+		FederatesWith: cmd.federatesWith,
+		Admin:         cmd.admin,
+		Downstream:    cmd.downstream,
+		Hint:          cmd.hint,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryCreateTTLs(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		x509SVIDTTL time.Duration
+		jwtSVIDTTL  time.Duration
+		wantX509TTL int32
+		wantJWTTTL  int32
+		wantErr     string
+	}{
+		{name: "server defaults"},
+		{name: "whole seconds", x509SVIDTTL: time.Hour, jwtSVIDTTL: 5 * time.Minute, wantX509TTL: 3600, wantJWTTTL: 300},
+		{name: "fractional ttl", x509SVIDTTL: 1500 * time.Millisecond, wantErr: "--ttl: invalid TTL 1.5s: must be a whole number of seconds"},
+		{name: "negative ttl", x509SVIDTTL: -time.Hour, wantErr: "--ttl: invalid TTL -1h0m0s: must not be negative"},
+		{name: "overflowing ttl", x509SVIDTTL: 2147483648 * time.Second, wantErr: "--ttl: invalid TTL 596523h14m8s: must be at most"},
+		{name: "fractional jwt ttl", jwtSVIDTTL: 100 * time.Millisecond, wantErr: "--jwt-ttl: invalid TTL 100ms: must be a whole number of seconds"},
+		{name: "negative jwt ttl", jwtSVIDTTL: -time.Second, wantErr: "--jwt-ttl: invalid TTL -1s: must not be negative"},
+		{name: "overflowing jwt ttl", jwtSVIDTTL: 1 << 62, wantErr: "--jwt-ttl: invalid TTL"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &entryCreate{
+				spiffeID:    "spiffe://example.org/workload",
+				parentID:    "spiffe://example.org/node",
+				selectors:   []string{"unix:uid:1000"},
+				x509SVIDTTL: tt.x509SVIDTTL,
+				jwtSVIDTTL:  tt.jwtSVIDTTL,
+			}
+			entry, err := cmd.entry()
+			assertErrorContains(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+			if entry.X509SvidTtl != tt.wantX509TTL {
+				t.Fatalf("expected X509-SVID TTL %d; got %d", tt.wantX509TTL, entry.X509SvidTtl)
+			}
+			if entry.JwtSvidTtl != tt.wantJWTTTL {
+				t.Fatalf("expected JWT-SVID TTL %d; got %d", tt.wantJWTTTL, entry.JwtSvidTtl)
+			}
+		})
+	}
+}
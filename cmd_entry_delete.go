@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/spf13/cobra"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+)
+
+func EntryDeleteCommand(config *rpcConfig) *cobra.Command {
+	impl := &entryDelete{config: config}
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Deletes registration entries",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringArrayVarP(&impl.ids, "id", "", nil, "ID of the entry to delete (repeatable)")
+	_ = cmd.RegisterFlagCompletionFunc("id", completeEntryIDs(config))
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+type entryDelete struct {
+	config *rpcConfig
+	ids    []string
+}
+
+func (cmd *entryDelete) Run(ctx context.Context, args []string) ([]byte, error) {
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := entryv1.NewEntryClient(conn).BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: cmd.ids})
+	if err != nil {
+		return nil, newRPCError("BatchDeleteEntry", err)
+	}
+
+	out := new(bytes.Buffer)
+	err = checkBatchResults(out, "BatchDeleteEntry", resp)
+	return out.Bytes(), err
+}
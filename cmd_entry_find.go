@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func EntryFindCommand(config *rpcConfig) *cobra.Command {
+	impl := &entryFind{config: config}
+	cmd := &cobra.Command{
+		Use:   "find",
+		Short: "Finds registration entries (all entries if no filters are given)",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.spiffeID, "spiffe-id", "", "", "SPIFFE ID of the entries")
+	cmd.Flags().StringVarP(&impl.parentID, "parent-id", "", "", "SPIFFE ID of the parent of the entries")
+	cmd.Flags().StringArrayVarP(&impl.selectors, "selector", "", nil, "Selector of the form TYPE:VALUE (repeatable)")
+	cmd.Flags().StringVarP(&impl.match, "match", "", "superset", "How selectors are matched (exact, subset, superset or any)")
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("spiffe-id", completeEntrySPIFFEIDs(config))
+	_ = cmd.RegisterFlagCompletionFunc("parent-id", completeEntrySPIFFEIDs(config))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	return cmd
+}
+
+type entryFind struct {
+	config    *rpcConfig
+	spiffeID  string
+	parentID  string
+	selectors []string
+	match     string
+	output    string
+}
+
+func (cmd *entryFind) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	filter, err := cmd.filter()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := listEntries(ctx, entryv1.NewEntryClient(conn), filter)
+	if err != nil {
+		return nil, err
+	}
+	return formatEntries(cmd.output, entries, &entryv1.ListEntriesResponse{Entries: entries})
+}
+
+func (cmd *entryFind) filter() (*entryv1.ListEntriesRequest_Filter, error) {
+	filter := new(entryv1.ListEntriesRequest_Filter)
+	var err error
+	if cmd.spiffeID != "" {
+		if filter.BySpiffeId, err = parseSPIFFEID(cmd.spiffeID); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.parentID != "" {
+		if filter.ByParentId, err = parseSPIFFEID(cmd.parentID); err != nil {
+			return nil, err
+		}
+	}
+	if len(cmd.selectors) > 0 {
+		if filter.BySelectors, err = parseSelectorMatch(cmd.selectors, cmd.match); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// listEntries lists all entries matching the filter, following pagination.
+func listEntries(ctx context.Context, client entryv1.EntryClient, filter *entryv1.ListEntriesRequest_Filter) ([]*types.Entry, error) {
+	var entries []*types.Entry
+	req := &entryv1.ListEntriesRequest{Filter: filter}
+	for {
+		resp, err := client.ListEntries(ctx, req)
+		if err != nil {
+			return nil, newRPCError("ListEntries", err)
+		}
+		entries = append(entries, resp.Entries...)
+		if resp.NextPageToken == "" {
+			return entries, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func EntryShowCommand(config *rpcConfig) *cobra.Command {
+	impl := &entryShow{config: config}
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Shows a registration entry",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.id, "id", "", "", "ID of the entry")
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("id", completeEntryIDs(config))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+type entryShow struct {
+	config *rpcConfig
+	id     string
+	output string
+}
+
+func (cmd *entryShow) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := entryv1.NewEntryClient(conn).GetEntry(ctx, &entryv1.GetEntryRequest{Id: cmd.id})
+	if err != nil {
+		return nil, newRPCError("GetEntry", err)
+	}
+	return formatEntries(cmd.output, []*types.Entry{entry}, entry)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// archiveVersion is the version of the archive format written by export.
+// It is bumped whenever the format changes incompatibly.
+const archiveVersion = 1
+
+// archive is a snapshot of server state. Each item is the protojson encoding
+// of the corresponding SPIRE API type.
+type archive struct {
+	Version                 int               `json:"version"`
+	ExportedAt              time.Time         `json:"exported_at"`
+	Entries                 []json.RawMessage `json:"entries"`
+	FederationRelationships []json.RawMessage `json:"federation_relationships"`
+	FederatedBundles        []json.RawMessage `json:"federated_bundles"`
+}
+
+func ExportCommand() *cobra.Command {
+	impl := &export{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports registration entries, federation relationships and federated bundles",
+		Long: `Exports registration entries, federation relationships and federated bundles
+from a SPIRE Server as a versioned JSON archive, which can be replayed into
+another server with "import".`,
+		Args: cobra.NoArgs,
+		RunE: runOut(impl),
+	}
+	addServerFlags(cmd.Flags(), &impl.config)
+	addConnectionFlags(cmd.Flags(), &impl.config)
+	cmd.Flags().DurationVarP(&impl.timeout, "timeout", "", time.Minute, "Timeout for the whole export")
+	return cmd
+}
+
+type export struct {
+	config  rpcConfig
+	timeout time.Duration
+}
+
+func (cmd *export) Run(ctx context.Context, args []string) ([]byte, error) {
+	// Large servers take longer to export than the command timeout.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cmd.timeout)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := listEntries(ctx, entryv1.NewEntryClient(conn), nil)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := listFederationRelationships(ctx, trustdomainv1.NewTrustDomainClient(conn))
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := listFederatedBundles(ctx, bundlev1.NewBundleClient(conn))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &archive{
+		Version:    archiveVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if a.Entries, err = marshalArchiveItems(entries); err != nil {
+		return nil, err
+	}
+	if a.FederationRelationships, err = marshalArchiveItems(relationships); err != nil {
+		return nil, err
+	}
+	if a.FederatedBundles, err = marshalArchiveItems(bundles); err != nil {
+		return nil, err
+	}
+
+	out, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func marshalArchiveItems[T proto.Message](items []T) ([]json.RawMessage, error) {
+	out := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		b, err := protojson.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// listFederatedBundles returns all federated bundles, following pagination.
+func listFederatedBundles(ctx context.Context, client bundlev1.BundleClient) ([]*types.Bundle, error) {
+	var bundles []*types.Bundle
+	req := &bundlev1.ListFederatedBundlesRequest{}
+	for {
+		resp, err := client.ListFederatedBundles(ctx, req)
+		if err != nil {
+			return nil, newRPCError("ListFederatedBundles", err)
+		}
+		bundles = append(bundles, resp.Bundles...)
+		if resp.NextPageToken == "" {
+			return bundles, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+func unmarshalArchiveItems[T proto.Message](items []json.RawMessage, newItem func() T) ([]T, error) {
+	out := make([]T, 0, len(items))
+	for i, b := range items {
+		item := newItem()
+		if err := protojson.Unmarshal(b, item); err != nil {
+			return nil, fmt.Errorf("item %d: %v", i, err)
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
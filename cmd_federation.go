@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	profileHTTPSWeb    = "https_web"
+	profileHTTPSSPIFFE = "https_spiffe"
+)
+
+func FederationCommand() *cobra.Command {
+	config := new(rpcConfig)
+	cmd := &cobra.Command{Use: "federation", Short: "Manages federation relationships via the TrustDomain API"}
+	addServerFlags(cmd.PersistentFlags(), config)
+	addConnectionFlags(cmd.PersistentFlags(), config)
+	cmd.PersistentFlags().DurationVarP(&config.timeout, "timeout", "", time.Minute, "Timeout for the command")
+	cmd.AddCommand(FederationCreateCommand(config))
+	cmd.AddCommand(FederationListCommand(config))
+	cmd.AddCommand(FederationShowCommand(config))
+	cmd.AddCommand(FederationUpdateCommand(config))
+	cmd.AddCommand(FederationDeleteCommand(config))
+	cmd.AddCommand(FederationRefreshCommand(config))
+	return cmd
+}
+
+// relationshipFlags are the flags describing a federation relationship,
+// shared by create and update.
+type relationshipFlags struct {
+	trustDomain      string
+	url              string
+	profile          string
+	endpointSPIFFEID string
+	bundleFile       string
+}
+
+func addRelationshipFlags(flags *pflag.FlagSet, rf *relationshipFlags) {
+	flags.StringVarP(&rf.trustDomain, "trust-domain", "", "", "Trust domain to federate with")
+	flags.StringVarP(&rf.url, "bundle-endpoint-url", "", "", "URL of the bundle endpoint of the trust domain")
+	flags.StringVarP(&rf.profile, "profile", "", "", "Bundle endpoint profile (https_web or https_spiffe)")
+	flags.StringVarP(&rf.endpointSPIFFEID, "endpoint-spiffe-id", "", "", "SPIFFE ID of the bundle endpoint server (https_spiffe only)")
+	flags.StringVarP(&rf.bundleFile, "bundle-file", "", "", "File containing the bundle of the trust domain (PEM, JWKS or protojson)")
+}
+
+// relationship returns the relationship described by the flags. Fields whose
+// flags are unset are left empty.
+func (rf *relationshipFlags) relationship() (*types.FederationRelationship, error) {
+	relationship := &types.FederationRelationship{
+		TrustDomain:       rf.trustDomain,
+		BundleEndpointUrl: rf.url,
+	}
+
+	switch rf.profile {
+	case "":
+		if rf.endpointSPIFFEID != "" {
+			return nil, fmt.Errorf("--endpoint-spiffe-id requires --profile %s", profileHTTPSSPIFFE)
+		}
+	case profileHTTPSWeb:
+		if rf.endpointSPIFFEID != "" {
+			return nil, fmt.Errorf("--endpoint-spiffe-id cannot be used with --profile %s", profileHTTPSWeb)
+		}
+		relationship.BundleEndpointProfile = &types.FederationRelationship_HttpsWeb{
+			HttpsWeb: &types.HTTPSWebProfile{},
+		}
+	case profileHTTPSSPIFFE:
+		if rf.endpointSPIFFEID == "" {
+			return nil, fmt.Errorf("--endpoint-spiffe-id is required with --profile %s", profileHTTPSSPIFFE)
+		}
+		if _, err := parseSPIFFEID(rf.endpointSPIFFEID); err != nil {
+			return nil, err
+		}
+		relationship.BundleEndpointProfile = &types.FederationRelationship_HttpsSpiffe{
+			HttpsSpiffe: &types.HTTPSSPIFFEProfile{EndpointSpiffeId: rf.endpointSPIFFEID},
+		}
+	default:
+		return nil, fmt.Errorf("unknown profile %q (expected %s or %s)", rf.profile, profileHTTPSWeb, profileHTTPSSPIFFE)
+	}
+
+	if rf.bundleFile != "" {
+		data, err := os.ReadFile(rf.bundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read bundle: %v", err)
+		}
+		if relationship.TrustDomainBundle, err = parseBundle(data, rf.trustDomain); err != nil {
+			return nil, err
+		}
+	}
+	return relationship, nil
+}
+
+// listFederationRelationships returns all federation relationships,
+// following pagination.
+func listFederationRelationships(ctx context.Context, client trustdomainv1.TrustDomainClient) ([]*types.FederationRelationship, error) {
+	var relationships []*types.FederationRelationship
+	req := &trustdomainv1.ListFederationRelationshipsRequest{}
+	for {
+		resp, err := client.ListFederationRelationships(ctx, req)
+		if err != nil {
+			return nil, newRPCError("ListFederationRelationships", err)
+		}
+		relationships = append(relationships, resp.FederationRelationships...)
+		if resp.NextPageToken == "" {
+			return relationships, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// formatRelationships renders federation relationships as a table or, when
+// json is requested, as the given message (which carries the relationships).
+func formatRelationships(format string, relationships []*types.FederationRelationship, m proto.Message) ([]byte, error) {
+	if format == outputJSON {
+		return append(marshalProtoJSON(m), '\n'), nil
+	}
+
+	rows := make([][]string, 0, len(relationships))
+	for _, relationship := range relationships {
+		profile, endpointSPIFFEID := "", ""
+		switch p := relationship.BundleEndpointProfile.(type) {
+		case *types.FederationRelationship_HttpsWeb:
+			profile = profileHTTPSWeb
+		case *types.FederationRelationship_HttpsSpiffe:
+			profile = profileHTTPSSPIFFE
+			endpointSPIFFEID = p.HttpsSpiffe.GetEndpointSpiffeId()
+		}
+		rows = append(rows, []string{
+			relationship.TrustDomain,
+			relationship.BundleEndpointUrl,
+			profile,
+			endpointSPIFFEID,
+		})
+	}
+	out := new(bytes.Buffer)
+	if err := writeTable(out, []string{"TRUST DOMAIN", "BUNDLE ENDPOINT URL", "PROFILE", "ENDPOINT SPIFFE ID"}, rows); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
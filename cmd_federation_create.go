@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func FederationCreateCommand(config *rpcConfig) *cobra.Command {
+	impl := &federationCreate{config: config}
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Creates a federation relationship",
+		Args:  cobra.NoArgs,
+		RunE:  runOutErr(impl),
+	}
+	addRelationshipFlags(cmd.Flags(), &impl.flags)
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeChoices(profileHTTPSWeb, profileHTTPSSPIFFE))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	_ = cmd.MarkFlagRequired("trust-domain")
+	_ = cmd.MarkFlagRequired("bundle-endpoint-url")
+	_ = cmd.MarkFlagRequired("profile")
+	return cmd
+}
+
+type federationCreate struct {
+	config *rpcConfig
+	flags  relationshipFlags
+	output string
+}
+
+func (cmd *federationCreate) Run(ctx context.Context, stderr io.Writer, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	relationship, err := cmd.flags.relationship()
+	if err != nil {
+		return nil, err
+	}
+	if relationship.BundleEndpointProfile == nil {
+		return nil, fmt.Errorf("--profile is required")
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := trustdomainv1.NewTrustDomainClient(conn).BatchCreateFederationRelationship(ctx, &trustdomainv1.BatchCreateFederationRelationshipRequest{
+		FederationRelationships: []*types.FederationRelationship{relationship},
+	})
+	if err != nil {
+		return nil, newRPCError("BatchCreateFederationRelationship", err)
+	}
+	status := new(bytes.Buffer)
+	if err := checkBatchResults(status, "BatchCreateFederationRelationship", resp); err != nil {
+		stderr.Write(status.Bytes())
+		return nil, err
+	}
+
+	created := resp.Results[0].FederationRelationship
+	return formatRelationships(cmd.output, []*types.FederationRelationship{created}, created)
+}
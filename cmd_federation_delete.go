@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/spf13/cobra"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+)
+
+func FederationDeleteCommand(config *rpcConfig) *cobra.Command {
+	impl := &federationDelete{config: config}
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Deletes federation relationships",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringArrayVarP(&impl.trustDomains, "trust-domain", "", nil, "Trust domain of the relationship to delete (repeatable)")
+	_ = cmd.RegisterFlagCompletionFunc("trust-domain", completeFederatedTrustDomains(config))
+	_ = cmd.MarkFlagRequired("trust-domain")
+	return cmd
+}
+
+type federationDelete struct {
+	config       *rpcConfig
+	trustDomains []string
+}
+
+func (cmd *federationDelete) Run(ctx context.Context, args []string) ([]byte, error) {
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := trustdomainv1.NewTrustDomainClient(conn).BatchDeleteFederationRelationship(ctx, &trustdomainv1.BatchDeleteFederationRelationshipRequest{
+		TrustDomains: cmd.trustDomains,
+	})
+	if err != nil {
+		return nil, newRPCError("BatchDeleteFederationRelationship", err)
+	}
+
+	out := new(bytes.Buffer)
+	err = checkBatchResults(out, "BatchDeleteFederationRelationship", resp)
+	return out.Bytes(), err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+)
+
+func FederationListCommand(config *rpcConfig) *cobra.Command {
+	impl := &federationList{config: config}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists federation relationships",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	return cmd
+}
+
+type federationList struct {
+	config *rpcConfig
+	output string
+}
+
+func (cmd *federationList) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	relationships, err := listFederationRelationships(ctx, trustdomainv1.NewTrustDomainClient(conn))
+	if err != nil {
+		return nil, err
+	}
+	return formatRelationships(cmd.output, relationships, &trustdomainv1.ListFederationRelationshipsResponse{FederationRelationships: relationships})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+)
+
+func FederationRefreshCommand(config *rpcConfig) *cobra.Command {
+	impl := &federationRefresh{config: config}
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Refreshes the bundle of a federated trust domain from its bundle endpoint",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.trustDomain, "trust-domain", "", "", "Trust domain of the relationship")
+	_ = cmd.RegisterFlagCompletionFunc("trust-domain", completeFederatedTrustDomains(config))
+	_ = cmd.MarkFlagRequired("trust-domain")
+	return cmd
+}
+
+type federationRefresh struct {
+	config      *rpcConfig
+	trustDomain string
+}
+
+func (cmd *federationRefresh) Run(ctx context.Context, args []string) ([]byte, error) {
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := trustdomainv1.NewTrustDomainClient(conn).RefreshBundle(ctx, &trustdomainv1.RefreshBundleRequest{
+		TrustDomain: cmd.trustDomain,
+	}); err != nil {
+		return nil, newRPCError("RefreshBundle", err)
+	}
+	return []byte(fmt.Sprintf("Refreshed bundle for %s\n", cmd.trustDomain)), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func FederationShowCommand(config *rpcConfig) *cobra.Command {
+	impl := &federationShow{config: config}
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Shows a federation relationship",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.trustDomain, "trust-domain", "", "", "Trust domain of the relationship")
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("trust-domain", completeFederatedTrustDomains(config))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	_ = cmd.MarkFlagRequired("trust-domain")
+	return cmd
+}
+
+type federationShow struct {
+	config      *rpcConfig
+	trustDomain string
+	output      string
+}
+
+func (cmd *federationShow) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	relationship, err := trustdomainv1.NewTrustDomainClient(conn).GetFederationRelationship(ctx, &trustdomainv1.GetFederationRelationshipRequest{
+		TrustDomain: cmd.trustDomain,
+	})
+	if err != nil {
+		return nil, newRPCError("GetFederationRelationship", err)
+	}
+	return formatRelationships(cmd.output, []*types.FederationRelationship{relationship}, relationship)
+}
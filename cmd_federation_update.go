@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func FederationUpdateCommand(config *rpcConfig) *cobra.Command {
+	impl := &federationUpdate{config: config}
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Updates a federation relationship",
+		Long: `Updates a federation relationship. Only the fields whose flags are given are
+updated; --profile must be given with --endpoint-spiffe-id.`,
+		Args: cobra.NoArgs,
+		RunE: runOutErr(impl),
+	}
+	addRelationshipFlags(cmd.Flags(), &impl.flags)
+	cmd.Flags().StringVarP(&impl.output, "output", "o", outputTable, "Output format (table or json)")
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeChoices(profileHTTPSWeb, profileHTTPSSPIFFE))
+	_ = cmd.RegisterFlagCompletionFunc("trust-domain", completeFederatedTrustDomains(config))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputTable, outputJSON))
+	_ = cmd.MarkFlagRequired("trust-domain")
+	return cmd
+}
+
+type federationUpdate struct {
+	config *rpcConfig
+	flags  relationshipFlags
+	output string
+}
+
+func (cmd *federationUpdate) Run(ctx context.Context, stderr io.Writer, args []string) ([]byte, error) {
+	if err := checkOutputFormat(cmd.output, outputTable, outputJSON); err != nil {
+		return nil, err
+	}
+
+	relationship, err := cmd.flags.relationship()
+	if err != nil {
+		return nil, err
+	}
+	mask := &types.FederationRelationshipMask{
+		BundleEndpointUrl:     relationship.BundleEndpointUrl != "",
+		BundleEndpointProfile: relationship.BundleEndpointProfile != nil,
+		TrustDomainBundle:     relationship.TrustDomainBundle != nil,
+	}
+	if !mask.BundleEndpointUrl && !mask.BundleEndpointProfile && !mask.TrustDomainBundle {
+		return nil, fmt.Errorf("nothing to update: one of --bundle-endpoint-url, --profile or --bundle-file is required")
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := trustdomainv1.NewTrustDomainClient(conn).BatchUpdateFederationRelationship(ctx, &trustdomainv1.BatchUpdateFederationRelationshipRequest{
+		FederationRelationships: []*types.FederationRelationship{relationship},
+		InputMask:               mask,
+	})
+	if err != nil {
+		return nil, newRPCError("BatchUpdateFederationRelationship", err)
+	}
+	status := new(bytes.Buffer)
+	if err := checkBatchResults(status, "BatchUpdateFederationRelationship", resp); err != nil {
+		stderr.Write(status.Bytes())
+		return nil, err
+	}
+
+	updated := resp.Results[0].FederationRelationship
+	return formatRelationships(cmd.output, []*types.FederationRelationship{updated}, updated)
+}
@@ -55,13 +55,21 @@ func (cmd *generateCSR) Run(_ context.Context, in []byte, args []string) ([]byte
 		return nil, fmt.Errorf("key is malformed: %v", err)
 	}
 
+	csrBytes, err := createCSR(key.(crypto.Signer), uris)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.BytesToBytes(csrBytes, codec.RawBytes(), cmd.csrFormat)
+}
+
+func createCSR(key crypto.Signer, uris []*url.URL) ([]byte, error) {
 	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
-		PublicKey: key.(crypto.Signer).Public(),
+		PublicKey: key.Public(),
 		URIs:      uris,
 	}, key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CSR: %v", err)
 	}
-
-	return codec.BytesToBytes(csrBytes, codec.RawBytes(), cmd.csrFormat)
+	return csrBytes, nil
 }
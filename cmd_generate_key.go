@@ -29,7 +29,7 @@ type generateKey struct {
 }
 
 func (cmd *generateKey) Run(_ context.Context, args []string) ([]byte, error) {
-	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key, err := newPrivateKey()
 	if err != nil {
 		return nil, err
 	}
@@ -39,3 +39,8 @@ func (cmd *generateKey) Run(_ context.Context, args []string) ([]byte, error) {
 	}
 	return codec.BytesToBytes(keyBytes, codec.RawBytes(), cmd.outFormat)
 }
+
+// newPrivateKey generates a key of the type used for SVIDs.
+func newPrivateKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
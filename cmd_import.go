@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	onConflictSkip      = "skip"
+	onConflictOverwrite = "overwrite"
+	onConflictFail      = "fail"
+
+	// importBatchSize bounds the number of items sent in each batch RPC so
+	// that large archives don't exceed the maximum message size.
+	importBatchSize = 100
+)
+
+func ImportCommand() *cobra.Command {
+	impl := &importArchive{}
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Imports an archive written by export into a SPIRE Server",
+		Long: `Imports an archive written by export (read from stdin) into a SPIRE Server.
+
+Federated bundles are imported first, then federation relationships, then
+registration entries. An item conflicts with an existing one when it has the
+same trust domain (bundles and federation relationships), or the same ID or
+the same SPIFFE ID, parent ID and selectors (entries). --on-conflict decides
+what happens to conflicting items:
+
+  skip       leave the existing item untouched
+  overwrite  replace the existing item with the imported one
+  fail       import nothing if any item conflicts (the default)
+
+Archives with more than one item for the same trust domain or entry are
+rejected before anything is imported.`,
+		Args: cobra.NoArgs,
+		RunE: runInOut(impl),
+	}
+	addServerFlags(cmd.Flags(), &impl.config)
+	addConnectionFlags(cmd.Flags(), &impl.config)
+	cmd.Flags().StringVarP(&impl.onConflict, "on-conflict", "", onConflictFail, "What to do with conflicting items (skip, overwrite or fail)")
+	cmd.Flags().DurationVarP(&impl.timeout, "timeout", "", time.Minute, "Timeout for the whole import")
+	_ = cmd.RegisterFlagCompletionFunc("on-conflict", completeChoices(onConflictSkip, onConflictOverwrite, onConflictFail))
+	return cmd
+}
+
+type importArchive struct {
+	config     rpcConfig
+	onConflict string
+	timeout    time.Duration
+}
+
+// importPlan holds the items to create and update for one kind of item.
+type importPlan[T proto.Message] struct {
+	create    []T
+	update    []T
+	conflicts []string
+}
+
+// skipped returns the number of conflicting items that are left untouched.
+func (p *importPlan[T]) skipped() int {
+	return len(p.conflicts) - len(p.update)
+}
+
+func (cmd *importArchive) Run(ctx context.Context, in []byte, args []string) ([]byte, error) {
+	switch cmd.onConflict {
+	case onConflictSkip, onConflictOverwrite, onConflictFail:
+	default:
+		return nil, fmt.Errorf("invalid --on-conflict %q: expected skip, overwrite or fail", cmd.onConflict)
+	}
+
+	items, err := parseArchive(in)
+	if err != nil {
+		return nil, err
+	}
+
+	// Large archives take longer to import than the command timeout.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cmd.timeout)
+	defer cancel()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	entryClient := entryv1.NewEntryClient(conn)
+	trustDomainClient := trustdomainv1.NewTrustDomainClient(conn)
+	bundleClient := bundlev1.NewBundleClient(conn)
+
+	currentEntries, err := listEntries(ctx, entryClient, nil)
+	if err != nil {
+		return nil, err
+	}
+	currentRelationships, err := listFederationRelationships(ctx, trustDomainClient)
+	if err != nil {
+		return nil, err
+	}
+	currentBundles, err := listFederatedBundles(ctx, bundleClient)
+	if err != nil {
+		return nil, err
+	}
+
+	bundlePlan := planBundleImport(items.bundles, currentBundles)
+	relationshipPlan := planRelationshipImport(items.relationships, currentRelationships)
+	entryPlan := planEntryImport(items.entries, currentEntries)
+
+	if cmd.onConflict == onConflictFail {
+		var conflicts []string
+		conflicts = append(conflicts, bundlePlan.conflicts...)
+		conflicts = append(conflicts, relationshipPlan.conflicts...)
+		conflicts = append(conflicts, entryPlan.conflicts...)
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("%d items conflict with existing items; nothing was imported:\n  %s", len(conflicts), strings.Join(conflicts, "\n  "))
+		}
+	}
+	if cmd.onConflict != onConflictOverwrite {
+		bundlePlan.update = nil
+		relationshipPlan.update = nil
+		entryPlan.update = nil
+	}
+
+	out := new(bytes.Buffer)
+	var errs []string
+	report := func(kind string, skipped, created, updated, failed int, err error) {
+		fmt.Fprintf(out, "%s: %d created, %d updated, %d skipped, %d failed\n", kind, created, updated, skipped, failed)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	created, updated, failed, err := importBatches(out, bundlePlan,
+		"BatchCreateFederatedBundle", func(batch []*types.Bundle) (proto.Message, []*types.Status, error) {
+			resp, err := bundleClient.BatchCreateFederatedBundle(ctx, &bundlev1.BatchCreateFederatedBundleRequest{Bundle: batch})
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, resultStatuses(resp.Results), nil
+		},
+		"BatchUpdateFederatedBundle", func(batch []*types.Bundle) (proto.Message, []*types.Status, error) {
+			resp, err := bundleClient.BatchUpdateFederatedBundle(ctx, &bundlev1.BatchUpdateFederatedBundleRequest{Bundle: batch})
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, resultStatuses(resp.Results), nil
+		})
+	report("federated bundles", bundlePlan.skipped(), created, updated, failed, err)
+
+	created, updated, failed, err = importBatches(out, relationshipPlan,
+		"BatchCreateFederationRelationship", func(batch []*types.FederationRelationship) (proto.Message, []*types.Status, error) {
+			resp, err := trustDomainClient.BatchCreateFederationRelationship(ctx, &trustdomainv1.BatchCreateFederationRelationshipRequest{FederationRelationships: batch})
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, resultStatuses(resp.Results), nil
+		},
+		"BatchUpdateFederationRelationship", func(batch []*types.FederationRelationship) (proto.Message, []*types.Status, error) {
+			resp, err := trustDomainClient.BatchUpdateFederationRelationship(ctx, &trustdomainv1.BatchUpdateFederationRelationshipRequest{FederationRelationships: batch})
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, resultStatuses(resp.Results), nil
+		})
+	report("federation relationships", relationshipPlan.skipped(), created, updated, failed, err)
+
+	created, updated, failed, err = importBatches(out, entryPlan,
+		"BatchCreateEntry", func(batch []*types.Entry) (proto.Message, []*types.Status, error) {
+			resp, err := entryClient.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: batch})
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, resultStatuses(resp.Results), nil
+		},
+		"BatchUpdateEntry", func(batch []*types.Entry) (proto.Message, []*types.Status, error) {
+			resp, err := entryClient.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{Entries: batch})
+			if err != nil {
+				return nil, nil, err
+			}
+			return resp, resultStatuses(resp.Results), nil
+		})
+	report("entries", entryPlan.skipped(), created, updated, failed, err)
+
+	if len(errs) > 0 {
+		return out.Bytes(), fmt.Errorf("import failed: %s", strings.Join(errs, "; "))
+	}
+	return out.Bytes(), nil
+}
+
+// archiveItems holds the items of an archive.
+type archiveItems struct {
+	bundles       []*types.Bundle
+	relationships []*types.FederationRelationship
+	entries       []*types.Entry
+}
+
+// parseArchive parses an archive written by export. Archives with more than
+// one item for the same trust domain (bundles and federation relationships),
+// or for the same ID or SPIFFE ID, parent ID and selectors (entries), are
+// rejected, since the items would conflict with each other.
+func parseArchive(in []byte) (*archiveItems, error) {
+	a := new(archive)
+	if err := json.Unmarshal(in, a); err != nil {
+		return nil, fmt.Errorf("unable to parse archive: %v", err)
+	}
+	if a.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d (expected %d)", a.Version, archiveVersion)
+	}
+	entries, err := unmarshalArchiveItems(a.Entries, func() *types.Entry { return new(types.Entry) })
+	if err != nil {
+		return nil, fmt.Errorf("invalid entry in archive: %v", err)
+	}
+	relationships, err := unmarshalArchiveItems(a.FederationRelationships, func() *types.FederationRelationship { return new(types.FederationRelationship) })
+	if err != nil {
+		return nil, fmt.Errorf("invalid federation relationship in archive: %v", err)
+	}
+	bundles, err := unmarshalArchiveItems(a.FederatedBundles, func() *types.Bundle { return new(types.Bundle) })
+	if err != nil {
+		return nil, fmt.Errorf("invalid federated bundle in archive: %v", err)
+	}
+
+	seenBundles := make(map[string]bool, len(bundles))
+	for _, bundle := range bundles {
+		if seenBundles[bundle.TrustDomain] {
+			return nil, fmt.Errorf("archive contains more than one federated bundle for %q", bundle.TrustDomain)
+		}
+		seenBundles[bundle.TrustDomain] = true
+	}
+	seenRelationships := make(map[string]bool, len(relationships))
+	for _, relationship := range relationships {
+		if seenRelationships[relationship.TrustDomain] {
+			return nil, fmt.Errorf("archive contains more than one federation relationship for %q", relationship.TrustDomain)
+		}
+		seenRelationships[relationship.TrustDomain] = true
+	}
+	seenIDs := make(map[string]bool, len(entries))
+	seenKeys := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Id != "" && seenIDs[entry.Id] {
+			return nil, fmt.Errorf("archive contains more than one entry with ID %q", entry.Id)
+		}
+		if key := entryKey(entry); seenKeys[key] {
+			return nil, fmt.Errorf("archive contains more than one entry for %s", describeEntry(entry))
+		}
+		seenIDs[entry.Id] = true
+		seenKeys[entryKey(entry)] = true
+	}
+
+	return &archiveItems{
+		bundles:       bundles,
+		relationships: relationships,
+		entries:       entries,
+	}, nil
+}
+
+func planBundleImport(bundles, current []*types.Bundle) *importPlan[*types.Bundle] {
+	existing := make(map[string]bool, len(current))
+	for _, bundle := range current {
+		existing[bundle.TrustDomain] = true
+	}
+	plan := new(importPlan[*types.Bundle])
+	for _, bundle := range bundles {
+		if existing[bundle.TrustDomain] {
+			plan.update = append(plan.update, bundle)
+			plan.conflicts = append(plan.conflicts, "federated bundle "+bundle.TrustDomain)
+			continue
+		}
+		plan.create = append(plan.create, bundle)
+	}
+	return plan
+}
+
+func planRelationshipImport(relationships, current []*types.FederationRelationship) *importPlan[*types.FederationRelationship] {
+	existing := make(map[string]bool, len(current))
+	for _, relationship := range current {
+		existing[relationship.TrustDomain] = true
+	}
+	plan := new(importPlan[*types.FederationRelationship])
+	for _, relationship := range relationships {
+		if existing[relationship.TrustDomain] {
+			plan.update = append(plan.update, relationship)
+			plan.conflicts = append(plan.conflicts, "federation relationship "+relationship.TrustDomain)
+			continue
+		}
+		plan.create = append(plan.create, relationship)
+	}
+	return plan
+}
+
+func planEntryImport(entries, current []*types.Entry) *importPlan[*types.Entry] {
+	byID := make(map[string]*types.Entry, len(current))
+	byKey := make(map[string]*types.Entry, len(current))
+	for _, entry := range current {
+		byID[entry.Id] = entry
+		byKey[entryKey(entry)] = entry
+	}
+	plan := new(importPlan[*types.Entry])
+	for _, entry := range entries {
+		entry = proto.Clone(entry).(*types.Entry)
+		// These are assigned by the server.
+		entry.CreatedAt = 0
+		entry.RevisionNumber = 0
+
+		have, ok := byID[entry.Id]
+		if !ok {
+			have, ok = byKey[entryKey(entry)]
+		}
+		if ok {
+			entry.Id = have.Id
+			plan.update = append(plan.update, entry)
+			plan.conflicts = append(plan.conflicts, "entry "+have.Id+" "+describeEntry(entry))
+			continue
+		}
+		plan.create = append(plan.create, entry)
+	}
+	return plan
+}
+
+// importBatchFunc issues a batch RPC for the items, returning the response
+// and the status of each result.
+type importBatchFunc[T proto.Message] func(batch []T) (proto.Message, []*types.Status, error)
+
+// resultStatuses returns the statuses of the results of a batch response.
+func resultStatuses[R interface{ GetStatus() *types.Status }](results []R) []*types.Status {
+	statuses := make([]*types.Status, 0, len(results))
+	for _, result := range results {
+		statuses = append(statuses, result.GetStatus())
+	}
+	return statuses
+}
+
+// importBatches creates and updates the items in the plan in batches,
+// returning how many were created, updated and failed. Results are only
+// written to out for batches with failures.
+func importBatches[T proto.Message](out *bytes.Buffer, plan *importPlan[T], createMethod string, create importBatchFunc[T], updateMethod string, update importBatchFunc[T]) (created, updated, failed int, err error) {
+	var errs []string
+	run := func(items []T, methodName string, call importBatchFunc[T]) int {
+		succeeded := 0
+		for start := 0; start < len(items); start += importBatchSize {
+			batch := items[start:min(start+importBatchSize, len(items))]
+			resp, statuses, err := call(batch)
+			if err != nil {
+				errs = append(errs, newRPCError(methodName, err).Error())
+				failed += len(batch)
+				continue
+			}
+			results := new(bytes.Buffer)
+			if err := checkBatchResults(results, methodName, resp); err != nil {
+				out.Write(results.Bytes())
+				errs = append(errs, err.Error())
+			}
+			ok := 0
+			for _, st := range statuses {
+				if st != nil && codes.Code(st.Code) == codes.OK {
+					ok++
+				}
+			}
+			succeeded += ok
+			failed += len(batch) - ok
+		}
+		return succeeded
+	}
+	created = run(plan.create, createMethod, create)
+	updated = run(plan.update, updateMethod, update)
+	if len(errs) > 0 {
+		err = errors.New(strings.Join(errs, "; "))
+	}
+	return created, updated, failed, err
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+func TestParseArchive(t *testing.T) {
+	entry := func(id, path string) string {
+		return fmt.Sprintf(`{"id": %q, "spiffe_id": {"trust_domain": "example.org", "path": %q}, "parent_id": {"trust_domain": "example.org", "path": "/node"}, "selectors": [{"type": "unix", "value": "uid:1000"}]}`, id, path)
+	}
+	archive := func(entries, relationships, bundles []string) string {
+		return fmt.Sprintf(`{"version": 1, "entries": [%s], "federation_relationships": [%s], "federated_bundles": [%s]}`,
+			strings.Join(entries, ","), strings.Join(relationships, ","), strings.Join(bundles, ","))
+	}
+	otherOrg := `{"trust_domain": "other.org"}`
+	anotherOrg := `{"trust_domain": "another.org"}`
+
+	for _, tt := range []struct {
+		name              string
+		in                string
+		wantEntries       []string
+		wantRelationships []string
+		wantBundles       []string
+		wantErr           string
+	}{
+		{
+			name: "empty",
+			in:   `{"version": 1}`,
+		},
+		{
+			name:              "items in archive order",
+			in:                archive([]string{entry("ID2", "/web"), entry("ID1", "/db")}, []string{otherOrg, anotherOrg}, []string{anotherOrg, otherOrg}),
+			wantEntries:       []string{"ID2", "ID1"},
+			wantRelationships: []string{"other.org", "another.org"},
+			wantBundles:       []string{"another.org", "other.org"},
+		},
+		{
+			name:        "entries without IDs",
+			in:          archive([]string{entry("", "/web"), entry("", "/db")}, nil, nil),
+			wantEntries: []string{"", ""},
+		},
+		{
+			name:    "malformed",
+			in:      `{"version": 1`,
+			wantErr: "unable to parse archive",
+		},
+		{
+			name:    "unsupported version",
+			in:      `{"version": 2}`,
+			wantErr: "unsupported archive version 2 (expected 1)",
+		},
+		{
+			name:    "invalid entry",
+			in:      archive([]string{`{"spiffe_id": "spiffe://example.org/web"}`}, nil, nil),
+			wantErr: "invalid entry in archive: item 0",
+		},
+		{
+			name:    "duplicate entry ID",
+			in:      archive([]string{entry("ID1", "/web"), entry("ID1", "/db")}, nil, nil),
+			wantErr: `archive contains more than one entry with ID "ID1"`,
+		},
+		{
+			name:    "duplicate entry",
+			in:      archive([]string{entry("ID1", "/web"), entry("ID2", "/web")}, nil, nil),
+			wantErr: "archive contains more than one entry for spiffe://example.org/web",
+		},
+		{
+			name:    "duplicate federation relationship",
+			in:      archive(nil, []string{otherOrg, anotherOrg, otherOrg}, nil),
+			wantErr: `archive contains more than one federation relationship for "other.org"`,
+		},
+		{
+			name:    "duplicate federated bundle",
+			in:      archive(nil, nil, []string{otherOrg, otherOrg}),
+			wantErr: `archive contains more than one federated bundle for "other.org"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := parseArchive([]byte(tt.in))
+			assertErrorContains(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			var entries, relationships, bundles []string
+			for _, entry := range items.entries {
+				entries = append(entries, entry.Id)
+			}
+			for _, relationship := range items.relationships {
+				relationships = append(relationships, relationship.TrustDomain)
+			}
+			for _, bundle := range items.bundles {
+				bundles = append(bundles, bundle.TrustDomain)
+			}
+			if !slices.Equal(entries, tt.wantEntries) {
+				t.Fatalf("expected entries %q; got %q", tt.wantEntries, entries)
+			}
+			if !slices.Equal(relationships, tt.wantRelationships) {
+				t.Fatalf("expected federation relationships %q; got %q", tt.wantRelationships, relationships)
+			}
+			if !slices.Equal(bundles, tt.wantBundles) {
+				t.Fatalf("expected federated bundles %q; got %q", tt.wantBundles, bundles)
+			}
+		})
+	}
+}
+
+func TestPlanEntryImport(t *testing.T) {
+	parentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/node"}
+	entry := func(id, path string) *types.Entry {
+		return &types.Entry{
+			Id:             id,
+			SpiffeId:       &types.SPIFFEID{TrustDomain: "example.org", Path: path},
+			ParentId:       parentID,
+			Selectors:      []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+			CreatedAt:      1700000000,
+			RevisionNumber: 3,
+		}
+	}
+
+	imported := []*types.Entry{
+		entry("A", "/a"),
+		// Conflicts by SPIFFE ID, parent ID and selectors.
+		entry("B", "/existing-by-key"),
+		entry("C", "/c"),
+		// Conflicts by ID.
+		entry("EXISTING1", "/renamed"),
+		entry("D", "/d"),
+	}
+	current := []*types.Entry{
+		entry("EXISTING1", "/existing"),
+		entry("EXISTING2", "/existing-by-key"),
+	}
+
+	plan := planEntryImport(imported, current)
+
+	var create, update []string
+	for _, entry := range plan.create {
+		create = append(create, entry.Id)
+	}
+	for _, entry := range plan.update {
+		update = append(update, entry.Id)
+	}
+	if want := []string{"A", "C", "D"}; !slices.Equal(create, want) {
+		t.Fatalf("expected to create %q in archive order; got %q", want, create)
+	}
+	if want := []string{"EXISTING2", "EXISTING1"}; !slices.Equal(update, want) {
+		t.Fatalf("expected to update %q in archive order; got %q", want, update)
+	}
+	if len(plan.conflicts) != 2 || plan.skipped() != 0 {
+		t.Fatalf("expected 2 conflicts; got %q", plan.conflicts)
+	}
+	for _, entry := range append(plan.create, plan.update...) {
+		if entry.CreatedAt != 0 || entry.RevisionNumber != 0 {
+			t.Fatalf("expected server assigned fields to be cleared; got %v", entry)
+		}
+	}
+	// The imported entries are left untouched.
+	if imported[1].Id != "B" || imported[0].CreatedAt == 0 {
+		t.Fatalf("expected imported entries to be left untouched")
+	}
+}
+
+func TestPlanBundleImport(t *testing.T) {
+	bundle := func(td string) *types.Bundle {
+		return &types.Bundle{TrustDomain: td}
+	}
+	plan := planBundleImport(
+		[]*types.Bundle{bundle("c.org"), bundle("a.org"), bundle("b.org"), bundle("d.org")},
+		[]*types.Bundle{bundle("a.org"), bundle("d.org")},
+	)
+
+	var create, update []string
+	for _, bundle := range plan.create {
+		create = append(create, bundle.TrustDomain)
+	}
+	for _, bundle := range plan.update {
+		update = append(update, bundle.TrustDomain)
+	}
+	if want := []string{"c.org", "b.org"}; !slices.Equal(create, want) {
+		t.Fatalf("expected to create %q; got %q", want, create)
+	}
+	if want := []string{"a.org", "d.org"}; !slices.Equal(update, want) {
+		t.Fatalf("expected to update %q; got %q", want, update)
+	}
+	if want := []string{"federated bundle a.org", "federated bundle d.org"}; !slices.Equal(plan.conflicts, want) {
+		t.Fatalf("expected conflicts %q; got %q", want, plan.conflicts)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/tls"
@@ -8,23 +9,33 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"reflect"
+	"time"
 
 	"github.com/go-openapi/inflect"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	agentdebugv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/agent/debug/v1"
+	delegatedidentityv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/agent/delegatedidentity/v1"
+	agentloggerv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/agent/logger/v1"
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	debugv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/debug/v1"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	localauthorityv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/localauthority/v1"
+	loggerv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/logger/v1"
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -49,26 +60,88 @@ func dasherizeAPIName(s string) string {
 	return apiNameRuleset.Dasherize(s)
 }
 
+// rpcEndpoint identifies where an API is served.
+type rpcEndpoint int
+
+const (
+	// serverEndpoint is the SPIRE Server API.
+	serverEndpoint rpcEndpoint = iota
+	// workloadEndpoint is the public SPIRE Agent (i.e. Workload) API.
+	workloadEndpoint
+	// agentAdminEndpoint is the SPIRE Agent admin API.
+	agentAdminEndpoint
+)
+
+// rpcAPI is an API whose RPCs are exposed as commands.
+type rpcAPI struct {
+	name     string
+	clientFn interface{}
+	endpoint rpcEndpoint
+}
+
+var rpcAPIs = []rpcAPI{
+	{name: "Agent", clientFn: agentv1.NewAgentClient},
+	{name: "Debug", clientFn: debugv1.NewDebugClient},
+	{name: "Entry", clientFn: entryv1.NewEntryClient},
+	{name: "Bundle", clientFn: bundlev1.NewBundleClient},
+	{name: "LocalAuthority", clientFn: localauthorityv1.NewLocalAuthorityClient},
+	{name: "Logger", clientFn: loggerv1.NewLoggerClient},
+	{name: "SVID", clientFn: svidv1.NewSVIDClient},
+	{name: "TrustDomain", clientFn: trustdomainv1.NewTrustDomainClient},
+	{name: "Workload", clientFn: workload.NewSpiffeWorkloadAPIClient, endpoint: workloadEndpoint},
+	{name: "AgentDebug", clientFn: agentdebugv1.NewDebugClient, endpoint: agentAdminEndpoint},
+	{name: "AgentLogger", clientFn: agentloggerv1.NewLoggerClient, endpoint: agentAdminEndpoint},
+	{name: "DelegatedIdentity", clientFn: delegatedidentityv1.NewDelegatedIdentityClient, endpoint: agentAdminEndpoint},
+}
+
 func RPCCommand() *cobra.Command {
 	cmd := &cobra.Command{Use: "rpc"}
-	cmd.AddCommand(makeServerAPICommands("Agent", agentv1.NewAgentClient))
-	cmd.AddCommand(makeServerAPICommands("Debug", debugv1.NewDebugClient))
-	cmd.AddCommand(makeServerAPICommands("Entry", entryv1.NewEntryClient))
-	cmd.AddCommand(makeServerAPICommands("Bundle", bundlev1.NewBundleClient))
-	cmd.AddCommand(makeServerAPICommands("SVID", svidv1.NewSVIDClient))
-	cmd.AddCommand(makeServerAPICommands("TrustDomain", trustdomainv1.NewTrustDomainClient))
-	cmd.AddCommand(makeAgentAPICommands("Workload", workload.NewSpiffeWorkloadAPIClient))
+	for _, api := range rpcAPIs {
+		switch api.endpoint {
+		case serverEndpoint:
+			cmd.AddCommand(makeServerAPICommands(api.name, api.clientFn))
+		case workloadEndpoint:
+			cmd.AddCommand(makeAgentAPICommands(api.name, api.clientFn))
+		case agentAdminEndpoint:
+			cmd.AddCommand(makeAgentAdminAPICommands(api.name, api.clientFn))
+		}
+	}
 	return cmd
 }
 
 type rpcConfig struct {
-	tcpAddr         string
-	udsAddr         string
-	useTCP          bool
-	useWorkloadAPI  bool
-	svidPath        string
-	workloadAPIAddr string
-	metadataPairs   []string
+	tcpAddr          string
+	udsAddr          string
+	useTCP           bool
+	useWorkloadAPI   bool
+	svidPath         string
+	workloadAPIAddr  string
+	metadataPairs    []string
+	strict           bool
+	retry            retryConfig
+	timeout          time.Duration
+	keepaliveTime    time.Duration
+	keepaliveTimeout time.Duration
+
+	// source provides the SVID used with --use-workload-api. It is created
+	// on first use and shared by later dials (e.g. retries) until closed.
+	source *workloadapi.X509Source
+}
+
+func (c *rpcConfig) dialOptions() []grpc.DialOption {
+	var options []grpc.DialOption
+	if c.keepaliveTime > 0 {
+		options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    c.keepaliveTime,
+			Timeout: c.keepaliveTimeout,
+		}))
+	}
+	return options
+}
+
+func addConnectionFlags(flags *pflag.FlagSet, config *rpcConfig) {
+	flags.DurationVarP(&config.keepaliveTime, "keepalive-time", "", 0, "Interval of keepalive pings sent when the connection is idle (disabled if zero)")
+	flags.DurationVarP(&config.keepaliveTimeout, "keepalive-timeout", "", 20*time.Second, "Time to wait for a keepalive ping to be acknowledged")
 }
 
 func makeServerAPICommands(groupName string, clientFn interface{}) *cobra.Command {
@@ -77,39 +150,71 @@ func makeServerAPICommands(groupName string, clientFn interface{}) *cobra.Comman
 		Use:   dasherizeAPIName(groupName),
 		Short: fmt.Sprintf("%s API RPCs", groupName),
 	}
-	cmd.PersistentFlags().StringVarP(&config.tcpAddr, "tcp-addr", "", "localhost:8081", "server TCP address")
-	cmd.PersistentFlags().StringVarP(&config.udsAddr, "uds-addr", "", "unix:///tmp/spire-server/private/api.sock", "server UDS address")
-	cmd.PersistentFlags().BoolVarP(&config.useTCP, "use-tcp", "", false, "Issue RPC via TCP")
-	cmd.PersistentFlags().StringVarP(&config.svidPath, "svid-path", "", "", "SVID to use to issue the RPC (implies --use-tcp)")
-	cmd.PersistentFlags().BoolVarP(&config.useWorkloadAPI, "use-workload-api", "", false, "Use the Workload API to obtain an SVID used to issue the RPC (implies --use-tcp)")
-	cmd.PersistentFlags().StringVarP(&config.workloadAPIAddr, "workload-api-addr", "", "unix:///tmp/spire-agent/public/api.sock", "Address to the Workload API socket")
+	addServerFlags(cmd.PersistentFlags(), config)
+	cmd.PersistentFlags().BoolVarP(&config.strict, "strict", "", false, "Fail if any result in a batch response has a non-OK status")
+	addConnectionFlags(cmd.PersistentFlags(), config)
+	addRetryFlags(cmd.PersistentFlags(), &config.retry)
+	cmd.PersistentFlags().DurationVarP(&config.timeout, "timeout", "", 30*time.Second, "Timeout for the RPC, including any retries")
 	addRPCCommands(cmd, groupName, clientFn, config)
 	return cmd
 }
 
+func addServerFlags(flags *pflag.FlagSet, config *rpcConfig) {
+	flags.StringVarP(&config.tcpAddr, "tcp-addr", "", "localhost:8081", "server TCP address")
+	flags.StringVarP(&config.udsAddr, "uds-addr", "", defaultServerUDSAddr(), "server UDS address (defaults to $SPIRE_SERVER_SOCKET)")
+	flags.BoolVarP(&config.useTCP, "use-tcp", "", false, "Issue RPC via TCP")
+	flags.StringVarP(&config.svidPath, "svid-path", "", "", "SVID to use to issue the RPC (implies --use-tcp)")
+	flags.BoolVarP(&config.useWorkloadAPI, "use-workload-api", "", false, "Use the Workload API to obtain an SVID used to issue the RPC (implies --use-tcp)")
+	flags.StringVarP(&config.workloadAPIAddr, "workload-api-addr", "", defaultWorkloadAPIAddr(), "Address to the Workload API socket (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+}
+
 func makeAgentAPICommands(groupName string, clientFn interface{}) *cobra.Command {
 	config := new(rpcConfig)
 	cmd := &cobra.Command{
 		Use:   dasherizeAPIName(groupName),
 		Short: fmt.Sprintf("%s API RPCs", groupName),
 	}
-	cmd.Flags().StringVarP(&config.udsAddr, "uds-addr", "", "unix:///tmp/spire-agent/public/api.sock", "agent UDS address")
+	cmd.PersistentFlags().StringVarP(&config.udsAddr, "uds-addr", "", defaultWorkloadAPIAddr(), "agent UDS address (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	addConnectionFlags(cmd.PersistentFlags(), config)
+	addRetryFlags(cmd.PersistentFlags(), &config.retry)
+	cmd.PersistentFlags().DurationVarP(&config.timeout, "timeout", "", 30*time.Second, "Timeout for the RPC, including any retries")
 	addRPCCommands(cmd, groupName, clientFn, config, setWorkloadAPIHeader)
 	return cmd
 }
 
+func makeAgentAdminAPICommands(groupName string, clientFn interface{}) *cobra.Command {
+	config := new(rpcConfig)
+	cmd := &cobra.Command{
+		Use:   dasherizeAPIName(groupName),
+		Short: fmt.Sprintf("%s API RPCs (agent admin socket)", groupName),
+	}
+	cmd.PersistentFlags().StringVarP(&config.udsAddr, "admin-uds-addr", "", "unix:///tmp/spire-agent/private/admin.sock", "agent admin UDS address")
+	addConnectionFlags(cmd.PersistentFlags(), config)
+	addRetryFlags(cmd.PersistentFlags(), &config.retry)
+	cmd.PersistentFlags().DurationVarP(&config.timeout, "timeout", "", 30*time.Second, "Timeout for the RPC, including any retries")
+	addRPCCommands(cmd, groupName, clientFn, config)
+	return cmd
+}
+
 func addRPCCommands(cmd *cobra.Command, groupName string, clientFn interface{}, config *rpcConfig, options ...rpcOption) {
 	for _, option := range options {
 		option(config)
 	}
 	fnv := reflect.ValueOf(clientFn)
-	fnt := reflect.TypeOf(clientFn)
+	for _, methodName := range rpcMethodNames(clientFn) {
+		cmd.AddCommand(makeRPCCommand(fnv, groupName, methodName, config))
+	}
+}
 
-	outt := fnt.Out(0)
+// rpcMethodNames returns the names of the RPC methods on the client returned
+// by the given client constructor.
+func rpcMethodNames(clientFn interface{}) []string {
+	outt := reflect.TypeOf(clientFn).Out(0)
+	names := make([]string, 0, outt.NumMethod())
 	for i := 0; i < outt.NumMethod(); i++ {
-		mt := outt.Method(i)
-		cmd.AddCommand(makeRPCCommand(fnv, groupName, mt.Name, config))
+		names = append(names, outt.Method(i).Name)
 	}
+	return names
 }
 
 func makeRPCCommand(newClientFn reflect.Value, groupName, methodName string, config *rpcConfig) *cobra.Command {
@@ -121,8 +226,20 @@ func makeRPCCommand(newClientFn reflect.Value, groupName, methodName string, con
 	cmd := &cobra.Command{
 		Use:   dasherizeAPIName(methodName),
 		Short: fmt.Sprintf("Invoke the %s %s RPC", groupName, methodName),
-		RunE:  runInOut(impl),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if !impl.request.readsStdin() {
+				cobraCmd.SetIn(bytes.NewReader(nil))
+			}
+			impl.stderr = cobraCmd.ErrOrStderr()
+			return runInOut(impl)(cobraCmd, args)
+		},
 	}
+	addRequestFlags(cmd.Flags(), &impl.request)
+	addOutputFlags(cmd.Flags(), &impl.output)
+	_ = cmd.RegisterFlagCompletionFunc("in-format", completeChoices(requestFormats...))
+	_ = cmd.RegisterFlagCompletionFunc("output", completeChoices(outputFormats...))
+	_ = cmd.RegisterFlagCompletionFunc("out-format", completeChoices(outputFormats...))
+	_ = cmd.RegisterFlagCompletionFunc("set", completeRequestFieldPaths(impl.newRequest))
 	return cmd
 }
 
@@ -132,45 +249,155 @@ type rpcCommand struct {
 	newClientFn reflect.Value
 	methodName  string
 	config      *rpcConfig
+	request     requestFlags
+	output      outputFlags
+	stderr      io.Writer
 }
 
-func (cmd *rpcCommand) Run(ctx context.Context, jsonIn []byte, args []string) ([]byte, error) {
-	makeReq := func(t reflect.Type) (reflect.Value, error) {
-		req := reflect.New(t.Elem())
-		if len(jsonIn) == 0 {
-			return zeroValue, fmt.Errorf("stdin was empty. Did you forget to pipe?")
-		}
-		if err := protojson.Unmarshal(jsonIn, req.Interface().(proto.Message)); err != nil {
-			return zeroValue, fmt.Errorf("unmarshaling request: %v", err)
-		}
-		return req, nil
+func (cmd *rpcCommand) Run(ctx context.Context, in []byte, args []string) ([]byte, error) {
+	if err := cmd.output.check(); err != nil {
+		return nil, err
 	}
+	jsonIn, err := cmd.request.build(in, cmd.newRequest)
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.config.check(); err != nil {
+		return nil, err
+	}
+	defer cmd.config.closeSource()
 
-	callErr := func(v reflect.Value) error {
-		if e := v.Interface(); e != nil {
-			st := status.Convert(e.(error))
-			return fmt.Errorf("rpc %s: %s: %s", cmd.methodName, st.Code(), st.Message())
+	// Retries and their backoff need more time than the command timeout.
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := cmd.callWithRetries(ctx, jsonIn)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	out, err := cmd.output.render(resp)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.config.strict {
+		if err := checkBatchResults(cmd.stderr, cmd.methodName, resp); err != nil {
+			return out, err
 		}
-		return nil
 	}
+	return out, nil
+}
+
+func (cmd *rpcCommand) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	return cmd.config.dial(ctx)
+}
+
+// dial returns a connection to the API configured by c.
+func (c *rpcConfig) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	options := c.dialOptions()
 
 	var conn *grpc.ClientConn
 	var err error
 	switch {
-	case cmd.config.svidPath != "":
-		conn, err = dialTCPWithSVID(cmd.config.tcpAddr, cmd.config.svidPath)
-	case cmd.config.useWorkloadAPI:
-		conn, err = dialTCPWithSVIDFromWorkloadAPI(ctx, cmd.config.tcpAddr, cmd.config.workloadAPIAddr)
-	case cmd.config.useTCP:
-		conn, err = dialInsecureTCP(cmd.config.tcpAddr)
+	case c.svidPath != "":
+		conn, err = dialTCPWithSVID(c.tcpAddr, c.svidPath, options...)
+	case c.useWorkloadAPI:
+		if err = checkWorkloadAPIAddr(c.workloadAPIAddr); err != nil {
+			return nil, err
+		}
+		if c.source == nil {
+			if c.source, err = newX509Source(ctx, c.workloadAPIAddr); err != nil {
+				return nil, err
+			}
+		}
+		if conn, err = dialTCPWithX509Source(c.tcpAddr, c.source, options...); err != nil {
+			c.closeSource()
+		}
+	case c.useTCP:
+		conn, err = dialInsecureTCP(c.tcpAddr, options...)
 	default:
-		conn, err = dialUDS(cmd.config.udsAddr)
+		var target string
+		if target, err = socketTarget(c.udsAddr); err == nil {
+			conn, err = dialUDS(target, options...)
+		}
 	}
 	if err != nil {
 		return nil, err
 	}
+	return conn, nil
+}
+
+// withTimeout returns a context bounded by the --timeout of the config
+// instead of the command timeout, e.g. for RPCs that are retried or paged.
+func (c *rpcConfig) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+}
+
+// check validates the addresses configured by c, so that a misconfiguration
+// is reported before dialing.
+func (c *rpcConfig) check() error {
+	switch {
+	case c.svidPath != "", c.useTCP:
+		return nil
+	case c.useWorkloadAPI:
+		return checkWorkloadAPIAddr(c.workloadAPIAddr)
+	default:
+		_, err := socketTarget(c.udsAddr)
+		return err
+	}
+}
+
+// closeSource closes the X509Source, if any, so that the next dial obtains
+// a new one.
+func (c *rpcConfig) closeSource() {
+	if c.source != nil {
+		c.source.Close()
+		c.source = nil
+	}
+}
+
+func (cmd *rpcCommand) call(ctx context.Context, jsonIn []byte) (proto.Message, error) {
+	conn, err := cmd.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer conn.Close()
 
+	ready := waitForReady(ctx, conn)
+	resp, err := cmd.invoke(ctx, conn, jsonIn)
+	if err != nil && !ready {
+		// The RPC failed because the connection could not be established
+		// so nothing was sent to the server.
+		cmd.config.closeSource()
+		return nil, dialError{err: err}
+	}
+	return resp, err
+}
+
+func (cmd *rpcCommand) invoke(ctx context.Context, conn *grpc.ClientConn, jsonIn []byte) (proto.Message, error) {
+	makeReq := func(t reflect.Type) (reflect.Value, error) {
+		req := reflect.New(t.Elem())
+		if len(bytes.TrimSpace(jsonIn)) == 0 {
+			// Request fields are never required by the protobuf schema
+			// so an empty request is sent as is and left for the server to
+			// validate.
+			return req, nil
+		}
+		if err := protojson.Unmarshal(jsonIn, req.Interface().(proto.Message)); err != nil {
+			return zeroValue, fmt.Errorf("unmarshaling request: %v", err)
+		}
+		return req, nil
+	}
+
+	callErr := func(v reflect.Value) error {
+		if e := v.Interface(); e != nil {
+			return rpcError{methodName: cmd.methodName, st: status.Convert(e.(error))}
+		}
+		return nil
+	}
+
 	if len(cmd.config.metadataPairs) > 0 {
 		ctx = metadata.AppendToOutgoingContext(ctx, cmd.config.metadataPairs...)
 	}
@@ -224,11 +451,73 @@ func (cmd *rpcCommand) Run(ctx context.Context, jsonIn []byte, args []string) ([
 		}
 	}
 
-	var jsonOut []byte
-	if !out[0].IsNil() {
-		jsonOut = marshalProtoJSON(out[0].Interface().(proto.Message))
+	if out[0].IsNil() {
+		return nil, nil
+	}
+	return out[0].Interface().(proto.Message), nil
+}
+
+// newRequest returns a new, empty request message for the RPC.
+func (cmd *rpcCommand) newRequest() proto.Message {
+	ct := cmd.newClientFn.Type().Out(0)
+	mt, _ := ct.MethodByName(cmd.methodName)
+	reqt := mt.Type.In(1)
+	if mt.Type.NumIn() == 2 {
+		// Streaming requests are sent via Send().
+		send, _ := mt.Type.Out(0).MethodByName("Send")
+		reqt = send.Type.In(0)
 	}
-	return jsonOut, nil
+	return reflect.New(reqt.Elem()).Interface().(proto.Message)
+}
+
+// isUnary returns true if the RPC is neither client nor server streaming.
+func (cmd *rpcCommand) isUnary() bool {
+	ct := cmd.newClientFn.Type().Out(0)
+	mt, ok := ct.MethodByName(cmd.methodName)
+	if !ok {
+		return false
+	}
+	// Methods on interface types do not include the receiver, so unary
+	// methods take a context, the request and the variadic call options.
+	if mt.Type.NumIn() != 3 {
+		return false
+	}
+	_, streaming := mt.Type.Out(0).MethodByName("Recv")
+	return !streaming
+}
+
+// rpcError is returned when an RPC fails. It preserves the gRPC status so
+// that callers can inspect the code.
+type rpcError struct {
+	methodName string
+	st         *status.Status
+}
+
+func (e rpcError) Error() string {
+	return fmt.Sprintf("rpc %s: %s: %s", e.methodName, e.st.Code(), e.st.Message())
+}
+
+func (e rpcError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+// newRPCError wraps an error returned by a generated client method.
+func newRPCError(methodName string, err error) error {
+	return rpcError{methodName: methodName, st: status.Convert(err)}
+}
+
+// dialError is returned when a connection to the API could not be
+// established. Nothing has been sent to the server when it is returned.
+type dialError struct {
+	err error
+}
+
+func (e dialError) Error() string {
+	return e.err.Error()
+}
+
+func (e dialError) Unwrap() error {
+	return e.err
 }
 
 func setWorkloadAPIHeader(c *rpcConfig) {
@@ -247,42 +536,57 @@ func asValues(args ...interface{}) []reflect.Value {
 	return out
 }
 
-func dialOptions(options ...grpc.DialOption) []grpc.DialOption {
-	return append(options, grpc.WithBlock(), grpc.FailOnNonTempDialError(true), grpc.WithReturnConnectionError())
-}
-
-func dialUDS(addr string) (*grpc.ClientConn, error) {
-	return grpc.Dial(addr, dialOptions(grpc.WithInsecure())...)
+func dialUDS(addr string, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.NewClient(addr, append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))...)
 }
 
-func dialTCP(addr string, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
-	return grpc.Dial(addr, dialOptions(grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))...)
+func dialTCP(addr string, tlsConfig *tls.Config, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.NewClient(addr, append(options, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))...)
 }
 
-func dialInsecureTCP(addr string) (*grpc.ClientConn, error) {
-	return dialTCP(addr, &tls.Config{InsecureSkipVerify: true})
+func dialInsecureTCP(addr string, options ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return dialTCP(addr, &tls.Config{InsecureSkipVerify: true}, options...)
 }
 
-func dialTCPWithSVID(addr, svidPath string) (*grpc.ClientConn, error) {
+func dialTCPWithSVID(addr, svidPath string, options ...grpc.DialOption) (*grpc.ClientConn, error) {
 	svid, key, err := loadSVID(svidPath)
 	if err != nil {
 		return nil, err
 	}
-	return dialTCP(addr, tlsConfigForSVID(svid, key))
+	return dialTCP(addr, tlsConfigForSVID(svid, key), options...)
 }
 
-func dialTCPWithSVIDFromWorkloadAPI(ctx context.Context, addr, workloadAPIPath string) (*grpc.ClientConn, error) {
+func newX509Source(ctx context.Context, workloadAPIPath string) (*workloadapi.X509Source, error) {
 	var opts []workloadapi.X509SourceOption
 	if workloadAPIPath != "" {
 		opts = append(opts, workloadapi.WithClientOptions(workloadapi.WithAddr(workloadAPIPath)))
 	}
-	source, err := workloadapi.NewX509Source(ctx, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return workloadapi.NewX509Source(ctx, opts...)
+}
 
+func dialTCPWithX509Source(addr string, source *workloadapi.X509Source, options ...grpc.DialOption) (*grpc.ClientConn, error) {
 	// TODO: stricter authorizer?
-	return dialTCP(addr, tlsconfig.MTLSClientConfig(source, source, tlsconfig.AuthorizeAny()))
+	return dialTCP(addr, tlsconfig.MTLSClientConfig(source, source, tlsconfig.AuthorizeAny()), options...)
+}
+
+// waitForReady starts connecting and waits until the connection is ready,
+// has failed, or the context is done. It returns true if the connection is
+// ready. If it is not, RPCs issued on the connection fail fast with the
+// reason the connection could not be established.
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) bool {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return true
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return false
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return false
+		}
+	}
 }
 
 func tlsConfigForSVID(svid []*x509.Certificate, key crypto.Signer) *tls.Config {
@@ -307,8 +611,8 @@ func rawCertsFromCertificates(certs []*x509.Certificate) [][]byte {
 }
 
 func loadSVID(path string) (_ []*x509.Certificate, _ crypto.Signer, err error) {
-	pemBytes, err := os.ReadFile(path)	
-	if os.IsNotExist(err) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
 		return nil, nil, fmt.Errorf("unable to load SVID: %v", err)
 	}
 
@@ -334,7 +638,7 @@ func loadSVID(path string) (_ []*x509.Certificate, _ crypto.Signer, err error) {
 				return nil, nil, fmt.Errorf("bad key in PEM block: %v", err)
 			}
 		default:
-			log.Panicf("Unexpected block type %q in PEM", block.Type)
+			return nil, nil, fmt.Errorf("unexpected block type %q in PEM (expected CERTIFICATE or PRIVATE KEY)", block.Type)
 		}
 	}
 
@@ -344,7 +648,11 @@ func loadSVID(path string) (_ []*x509.Certificate, _ crypto.Signer, err error) {
 	if key == nil {
 		return nil, nil, errors.New("no key in PEM file")
 	}
-	return certs, key.(crypto.Signer), nil
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported key type %T in PEM file", key)
+	}
+	return certs, signer, nil
 }
 
 func marshalProtoJSON(m proto.Message) []byte {
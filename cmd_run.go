@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+)
+
+func RunCommand() *cobra.Command {
+	impl := &runPlanCommand{}
+	cmd := &cobra.Command{
+		Use:   "run PLAN",
+		Short: "Executes a plan of RPCs from a YAML (or JSON) file",
+		Long: `Executes a plan of RPCs from a YAML (or JSON) file, e.g.:
+
+  vars:
+    td: example.org
+  steps:
+  - name: create entry
+    api: entry
+    method: batch-create-entry
+    request:
+      entries:
+      - spiffe_id: {trust_domain: "${td}", path: /workload}
+        parent_id: {trust_domain: "${td}", path: /agent}
+        selectors: [{type: unix, value: "uid:1000"}]
+    capture:
+      entry_id: .results[0].entry.id
+  - name: fetch entry
+    api: entry
+    method: get-entry
+    request:
+      id: ${entry_id}
+  - name: entry is gone
+    api: entry
+    method: get-entry
+    request:
+      id: does-not-exist
+    expect:
+      status: NotFound
+
+Strings in requests may reference plan variables, captured values or
+environment variables as ${NAME}. Steps are expected to succeed unless
+expect.status names another gRPC status code.
+
+The result of each step is printed as it completes: PASS, FAIL if the RPC
+completed with an unexpected status, or ERROR if the step could not be
+executed (e.g. an undefined variable or a request that does not match the
+method).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			cobraCmd.SilenceUsage = true
+			return impl.Run(cobraCmd.Context(), cobraCmd.OutOrStdout(), args)
+		},
+	}
+	addSessionFlags(cmd.Flags(), &impl.session)
+	cmd.Flags().DurationVarP(&impl.rpcTimeout, "rpc-timeout", "", cmdTimeout, "Timeout for each RPC")
+	cmd.Flags().BoolVarP(&impl.keepGoing, "keep-going", "", false, "Keep executing steps after a step fails")
+	cmd.Flags().BoolVarP(&impl.verbose, "verbose", "v", false, "Print the response for each step")
+	return cmd
+}
+
+type runPlan struct {
+	Vars  map[string]interface{} `json:"vars"`
+	Steps []runStep              `json:"steps"`
+}
+
+type runStep struct {
+	Name    string            `json:"name"`
+	API     string            `json:"api"`
+	Method  string            `json:"method"`
+	Request json.RawMessage   `json:"request"`
+	Expect  runExpect         `json:"expect"`
+	Capture map[string]string `json:"capture"`
+}
+
+type runExpect struct {
+	Status string `json:"status"`
+}
+
+type runPlanCommand struct {
+	session    rpcSession
+	rpcTimeout time.Duration
+	keepGoing  bool
+	verbose    bool
+}
+
+// stepStatusError is returned when the RPC of a step completes with a status
+// other than the expected one. Any other error returned by a step is local to
+// the client.
+type stepStatusError struct {
+	expected codes.Code
+	err      error
+}
+
+func (e stepStatusError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("expected status %s; got %s", e.expected, codes.OK)
+	}
+	if e.expected == codes.OK {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("expected status %s; got %v", e.expected, e.err)
+}
+
+func (cmd *runPlanCommand) Run(ctx context.Context, out io.Writer, args []string) error {
+	planBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("unable to read plan: %v", err)
+	}
+	plan := new(runPlan)
+	if err := yaml.UnmarshalStrict(planBytes, plan); err != nil {
+		return fmt.Errorf("unable to parse plan: %v", err)
+	}
+
+	// The plan as a whole outlives the command timeout. Each RPC is instead
+	// bounded by the RPC timeout.
+	ctx = context.WithoutCancel(ctx)
+
+	cmd.session.open()
+	defer cmd.session.Close()
+
+	vars := make(map[string]interface{})
+	for name, value := range plan.Vars {
+		vars[name] = value
+	}
+
+	failed := 0
+	for i, step := range plan.Steps {
+		name := step.Name
+		if name == "" {
+			name = step.API + " " + step.Method
+		}
+		resp, err := cmd.runStep(ctx, step, vars)
+		if err != nil {
+			failed++
+			result := "ERROR"
+			if errors.As(err, new(stepStatusError)) {
+				result = "FAIL"
+			}
+			fmt.Fprintf(out, "%s [%d] %s: %v\n", result, i+1, name, err)
+			if !cmd.keepGoing {
+				break
+			}
+			continue
+		}
+		fmt.Fprintf(out, "PASS [%d] %s\n", i+1, name)
+		if cmd.verbose && len(resp) > 0 {
+			fmt.Fprintf(out, "%s\n", resp)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d steps failed", failed, len(plan.Steps))
+	}
+	return nil
+}
+
+func (cmd *runPlanCommand) runStep(ctx context.Context, step runStep, vars map[string]interface{}) ([]byte, error) {
+	expectCode := codes.OK
+	if step.Expect.Status != "" {
+		parsed, err := parseCodes([]string{step.Expect.Status})
+		if err != nil {
+			return nil, err
+		}
+		for code := range parsed {
+			expectCode = code
+		}
+	}
+
+	jsonIn := []byte("{}")
+	if len(step.Request) > 0 && string(step.Request) != "null" {
+		request, err := decodeJSON(step.Request)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request: %v", err)
+		}
+		request, err = substituteVars(request, lookupVar(vars))
+		if err != nil {
+			return nil, err
+		}
+		jsonIn, err = json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cmd.rpcTimeout)
+	defer cancel()
+
+	resp, err := cmd.session.Call(ctx, step.API, step.Method, jsonIn)
+	code := codes.OK
+	if err != nil {
+		var re rpcError
+		if !errors.As(err, &re) {
+			return nil, err
+		}
+		code = re.st.Code()
+	}
+	if code != expectCode {
+		return nil, stepStatusError{expected: expectCode, err: err}
+	}
+	if resp == nil {
+		return nil, nil
+	}
+
+	jsonOut, err := protojson.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(step.Capture) > 0 {
+		decoded, err := decodeJSON(jsonOut)
+		if err != nil {
+			return nil, err
+		}
+		for name, expr := range step.Capture {
+			value, err := evalPath(decoded, expr)
+			if err != nil {
+				return nil, fmt.Errorf("capturing %s: %v", name, err)
+			}
+			if value == nil {
+				return nil, fmt.Errorf("capturing %s: %q matched nothing", name, expr)
+			}
+			vars[name] = value
+		}
+	}
+	return marshalProtoJSON(resp), nil
+}
+
+var varRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// lookupVar returns a function that looks up variables in vars, falling back
+// to the environment.
+func lookupVar(vars map[string]interface{}) func(string) (interface{}, bool) {
+	return func(name string) (interface{}, bool) {
+		if value, ok := vars[name]; ok {
+			return value, true
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		return nil, false
+	}
+}
+
+// substituteVars replaces ${NAME} references in the strings of a decoded
+// JSON value. A string consisting of a single reference is replaced by the
+// value itself (which need not be a string).
+func substituteVars(v interface{}, lookup func(string) (interface{}, bool)) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			value, err := substituteVars(value, lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = value
+		}
+		return v, nil
+	case []interface{}:
+		for i, value := range v {
+			value, err := substituteVars(value, lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = value
+		}
+		return v, nil
+	case string:
+		if m := varRE.FindStringSubmatch(v); m != nil && m[0] == v {
+			value, ok := lookup(m[1])
+			if !ok {
+				return nil, fmt.Errorf("undefined variable %q", m[1])
+			}
+			return value, nil
+		}
+		var err error
+		s := varRE.ReplaceAllStringFunc(v, func(ref string) string {
+			name := varRE.FindStringSubmatch(ref)[1]
+			value, ok := lookup(name)
+			if !ok {
+				err = fmt.Errorf("undefined variable %q", name)
+				return ref
+			}
+			if s, ok := value.(string); ok {
+				return s
+			}
+			b, _ := json.Marshal(value)
+			return string(b)
+		})
+		return s, err
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,9 @@
+package main
+
+import "github.com/spf13/cobra"
+
+func ServeCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "serve", Short: "Serves local test endpoints"}
+	cmd.AddCommand(ServeBundleEndpointCommand())
+	return cmd
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+func ServeBundleEndpointCommand() *cobra.Command {
+	impl := &serveBundleEndpoint{}
+	cmd := &cobra.Command{
+		Use:   "bundle-endpoint",
+		Short: "Serves a SPIFFE bundle endpoint for federation testing",
+		Long: `Serves a SPIFFE bundle endpoint for federation testing.
+
+The bundle file (PEM, JWKS or protojson) is served as a SPIFFE bundle. It is
+reloaded in the background when it changes (checked every --reload-interval)
+or when SIGHUP is received; if the new contents are invalid, the last good
+bundle continues to be served. Each fetch is logged to stdout.
+
+With the https_web profile the endpoint authenticates with the certificate
+and key given by --cert and --key. With the https_spiffe profile it
+authenticates with an X509-SVID, either from --svid-path or from the
+Workload API.
+
+The command runs until interrupted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			cobraCmd.SilenceUsage = true
+			return impl.Run(cobraCmd.Context(), cobraCmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().StringVarP(&impl.bundlePath, "bundle", "", "", "File containing the bundle to serve (PEM, JWKS or protojson)")
+	cmd.Flags().StringVarP(&impl.trustDomain, "trust-domain", "", "", "Trust domain of the bundle")
+	cmd.Flags().StringVarP(&impl.listenAddr, "listen", "", ":8443", "Address to listen on")
+	cmd.Flags().StringVarP(&impl.profile, "profile", "", profileHTTPSWeb, "Bundle endpoint profile (https_web or https_spiffe)")
+	cmd.Flags().StringVarP(&impl.certPath, "cert", "", "", "Certificate chain to serve with (https_web only)")
+	cmd.Flags().StringVarP(&impl.keyPath, "key", "", "", "Key to serve with (https_web only)")
+	cmd.Flags().StringVarP(&impl.svidPath, "svid-path", "", "", "SVID (certificates and key) to serve with (https_spiffe only)")
+	cmd.Flags().BoolVarP(&impl.useWorkloadAPI, "use-workload-api", "", false, "Obtain the SVID to serve with from the Workload API (https_spiffe only)")
+	cmd.Flags().StringVarP(&impl.workloadAPIAddr, "workload-api-addr", "", defaultWorkloadAPIAddr(), "Address to the Workload API socket (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	cmd.Flags().DurationVarP(&impl.reloadInterval, "reload-interval", "", time.Second, "Interval at which the bundle file is checked for changes")
+	_ = cmd.RegisterFlagCompletionFunc("profile", completeChoices(profileHTTPSWeb, profileHTTPSSPIFFE))
+	_ = cmd.MarkFlagRequired("bundle")
+	_ = cmd.MarkFlagRequired("trust-domain")
+	return cmd
+}
+
+type serveBundleEndpoint struct {
+	bundlePath      string
+	trustDomain     string
+	listenAddr      string
+	profile         string
+	certPath        string
+	keyPath         string
+	svidPath        string
+	useWorkloadAPI  bool
+	workloadAPIAddr string
+	reloadInterval  time.Duration
+
+	log *log.Logger
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	jwks    []byte
+}
+
+func (cmd *serveBundleEndpoint) Run(ctx context.Context, out io.Writer) error {
+	cmd.log = log.New(out, "", log.LstdFlags)
+
+	if cmd.reloadInterval <= 0 {
+		return fmt.Errorf("--reload-interval must be positive")
+	}
+	if err := cmd.reload(true); err != nil {
+		return err
+	}
+
+	// Serving outlives the command timeout. It runs until interrupted.
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tlsConfig, closeSource, err := cmd.tlsConfig(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+
+	go cmd.watchBundle(ctx)
+
+	listener, err := net.Listen("tcp", cmd.listenAddr)
+	if err != nil {
+		return fmt.Errorf("unable to listen: %v", err)
+	}
+
+	server := &http.Server{
+		Handler:           http.HandlerFunc(cmd.serveHTTP),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          cmd.log,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	cmd.log.Printf("Serving %s bundle endpoint on %s", cmd.profile, listener.Addr())
+	if err := server.ServeTLS(listener, "", ""); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (cmd *serveBundleEndpoint) tlsConfig(ctx context.Context) (*tls.Config, func(), error) {
+	noop := func() {}
+	switch cmd.profile {
+	case profileHTTPSWeb:
+		if cmd.certPath == "" || cmd.keyPath == "" {
+			return nil, nil, fmt.Errorf("--cert and --key are required with --profile %s", profileHTTPSWeb)
+		}
+		cert, err := tls.LoadX509KeyPair(cmd.certPath, cmd.keyPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to load certificate: %v", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, noop, nil
+	case profileHTTPSSPIFFE:
+		switch {
+		case cmd.svidPath != "":
+			svid, key, err := loadSVID(cmd.svidPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			return &tls.Config{
+				Certificates: []tls.Certificate{
+					{
+						Certificate: rawCertsFromCertificates(svid),
+						PrivateKey:  key,
+					},
+				},
+			}, noop, nil
+		case cmd.useWorkloadAPI:
+			if err := checkWorkloadAPIAddr(cmd.workloadAPIAddr); err != nil {
+				return nil, nil, err
+			}
+			source, err := workloadapi.NewX509Source(ctx, workloadapi.WithClientOptions(workloadapi.WithAddr(cmd.workloadAPIAddr)))
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to obtain SVID from the Workload API: %v", err)
+			}
+			return tlsconfig.TLSServerConfig(source), func() { source.Close() }, nil
+		default:
+			return nil, nil, fmt.Errorf("one of --svid-path or --use-workload-api is required with --profile %s", profileHTTPSSPIFFE)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown profile %q (expected %s or %s)", cmd.profile, profileHTTPSWeb, profileHTTPSSPIFFE)
+	}
+}
+
+func (cmd *serveBundleEndpoint) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		cmd.log.Printf("%s %s from %s: %d", r.Method, r.URL.Path, r.RemoteAddr, http.StatusMethodNotAllowed)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	cmd.mu.Lock()
+	jwks := cmd.jwks
+	cmd.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(jwks)
+	cmd.log.Printf("%s %s from %s: %d", r.Method, r.URL.Path, r.RemoteAddr, http.StatusOK)
+}
+
+// watchBundle reloads the bundle when the file changes or on SIGHUP, until
+// ctx is done.
+func (cmd *serveBundleEndpoint) watchBundle(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(cmd.reloadInterval)
+	defer ticker.Stop()
+	for {
+		force := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-hup:
+			force = true
+		}
+		if err := cmd.reload(force); err != nil {
+			cmd.log.Printf("Unable to reload bundle; serving the last good bundle: %v", err)
+		}
+	}
+}
+
+// reload reloads the bundle if forced or if the file has changed since it
+// was last read. A file with invalid contents is not read again until it
+// changes.
+func (cmd *serveBundleEndpoint) reload(force bool) error {
+	info, err := os.Stat(cmd.bundlePath)
+	if err != nil {
+		return fmt.Errorf("unable to read bundle: %v", err)
+	}
+
+	cmd.mu.Lock()
+	unchanged := info.ModTime().Equal(cmd.modTime) && info.Size() == cmd.size
+	cmd.mu.Unlock()
+	if unchanged && !force {
+		return nil
+	}
+
+	data, err := os.ReadFile(cmd.bundlePath)
+	if err != nil {
+		return fmt.Errorf("unable to read bundle: %v", err)
+	}
+	jwks, err := cmd.marshalBundle(data)
+
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	cmd.modTime = info.ModTime()
+	cmd.size = info.Size()
+	if err != nil {
+		return err
+	}
+	if cmd.jwks != nil {
+		cmd.log.Printf("Reloaded bundle from %s", cmd.bundlePath)
+	}
+	cmd.jwks = jwks
+	return nil
+}
+
+// marshalBundle parses a bundle file and marshals it as a SPIFFE bundle.
+func (cmd *serveBundleEndpoint) marshalBundle(data []byte) ([]byte, error) {
+	bundle, err := parseBundle(data, cmd.trustDomain)
+	if err != nil {
+		return nil, err
+	}
+	spiffeBundle, err := spiffeBundleFromBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+	jwks, err := spiffeBundle.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal bundle: %v", err)
+	}
+	return jwks, nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+)
+
+func ShellCommand() *cobra.Command {
+	impl := &shell{}
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Interactive shell for issuing RPCs over long-lived connections",
+		Long: `Interactive shell for issuing RPCs over long-lived connections.
+
+Each line names an API, a method and the request, e.g.:
+
+  entry list-entries {filter: {by_spiffe_id: {trust_domain: 'example.org', path: '/foo'}}}
+
+The request may be JSON or relaxed JSON (comments, single quoted strings,
+unquoted keys and trailing commas). An empty request is sent as {}.`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			cobraCmd.SilenceUsage = true
+			return impl.Run(cobraCmd.Context(), cobraCmd.OutOrStdout(), cobraCmd.ErrOrStderr())
+		},
+	}
+	addSessionFlags(cmd.Flags(), &impl.session)
+	cmd.Flags().StringVarP(&impl.historyFile, "history-file", "", defaultHistoryFile(), "File to persist command history to (disabled if empty)")
+	cmd.Flags().DurationVarP(&impl.rpcTimeout, "rpc-timeout", "", cmdTimeout, "Timeout for each RPC")
+	return cmd
+}
+
+type shell struct {
+	session     rpcSession
+	historyFile string
+	rpcTimeout  time.Duration
+}
+
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".spire-pipe_history")
+}
+
+func (sh *shell) Run(ctx context.Context, out, stderr io.Writer) error {
+	// The shell outlives the command timeout. Each RPC is instead bounded
+	// by the RPC timeout.
+	ctx = context.WithoutCancel(ctx)
+
+	sh.session.open()
+	defer sh.session.Close()
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetCompleter(sh.complete)
+
+	if sh.historyFile != "" {
+		if f, err := os.Open(sh.historyFile); err == nil {
+			_, _ = line.ReadHistory(f)
+			f.Close()
+		}
+		defer sh.writeHistory(line, stderr)
+	}
+
+	for {
+		input, err := line.Prompt("spire-pipe> ")
+		switch {
+		case errors.Is(err, liner.ErrPromptAborted):
+			continue
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		line.AppendHistory(input)
+
+		switch input {
+		case "exit", "quit":
+			return nil
+		case "help":
+			sh.help(out)
+			continue
+		}
+
+		resp, err := sh.exec(ctx, input)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			continue
+		}
+		if len(resp) > 0 {
+			fmt.Fprintf(out, "%s\n", resp)
+		}
+	}
+}
+
+func (sh *shell) writeHistory(line *liner.State, stderr io.Writer) {
+	f, err := os.OpenFile(sh.historyFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Fprintf(stderr, "Unable to write history: %v\n", err)
+		return
+	}
+	defer f.Close()
+	if _, err := line.WriteHistory(f); err != nil {
+		fmt.Fprintf(stderr, "Unable to write history: %v\n", err)
+	}
+}
+
+func (sh *shell) exec(ctx context.Context, input string) ([]byte, error) {
+	apiName, rest, _ := strings.Cut(input, " ")
+	methodName, request, _ := strings.Cut(strings.TrimSpace(rest), " ")
+
+	request = strings.TrimSpace(request)
+	if request == "" {
+		request = "{}"
+	}
+	jsonIn, err := relaxJSON([]byte(request))
+	if err != nil {
+		return nil, fmt.Errorf("malformed request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sh.rpcTimeout)
+	defer cancel()
+
+	resp, err := sh.session.Call(ctx, apiName, methodName, jsonIn)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	return marshalProtoJSON(resp), nil
+}
+
+func (sh *shell) help(out io.Writer) {
+	fmt.Fprintln(out, "Usage: API METHOD [REQUEST]")
+	fmt.Fprintln(out, "       help | exit | quit")
+	for _, api := range rpcAPIs {
+		fmt.Fprintf(out, "\n%s:\n", dasherizeAPIName(api.name))
+		for _, methodName := range rpcMethodNames(api.clientFn) {
+			fmt.Fprintf(out, "  %s\n", dasherizeAPIName(methodName))
+		}
+	}
+}
+
+// complete returns completions for the API name and method name.
+func (sh *shell) complete(line string) []string {
+	apiName, rest, hasAPI := strings.Cut(line, " ")
+	if !hasAPI {
+		var out []string
+		for _, name := range append(rpcAPINames(), "help", "exit", "quit") {
+			if strings.HasPrefix(name, apiName) {
+				out = append(out, name+" ")
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	if strings.Contains(rest, " ") {
+		return nil
+	}
+	api, ok := lookupRPCAPI(apiName)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, methodName := range rpcMethodNames(api.clientFn) {
+		if name := dasherizeAPIName(methodName); strings.HasPrefix(name, rest) {
+			out = append(out, apiName+" "+name+" ")
+		}
+	}
+	sort.Strings(out)
+	return out
+}
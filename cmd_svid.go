@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func SVIDCommand() *cobra.Command {
+	config := new(rpcConfig)
+	cmd := &cobra.Command{Use: "svid", Short: "Mints SVIDs via the SVID API"}
+	addServerFlags(cmd.PersistentFlags(), config)
+	addConnectionFlags(cmd.PersistentFlags(), config)
+	cmd.PersistentFlags().DurationVarP(&config.timeout, "timeout", "", time.Minute, "Timeout for the command")
+
+	mint := &cobra.Command{Use: "mint", Short: "Mints an SVID"}
+	mint.AddCommand(SVIDMintX509Command(config))
+	mint.AddCommand(SVIDMintJWTCommand(config))
+	cmd.AddCommand(mint)
+	return cmd
+}
+
+// ttlSeconds converts a TTL flag to the whole number of seconds taken by
+// the SVID and entry APIs. Zero selects the server's default TTL.
+func ttlSeconds(ttl time.Duration) (int32, error) {
+	switch {
+	case ttl < 0:
+		return 0, fmt.Errorf("invalid TTL %s: must not be negative", ttl)
+	case ttl%time.Second != 0:
+		return 0, fmt.Errorf("invalid TTL %s: must be a whole number of seconds", ttl)
+	case ttl/time.Second > math.MaxInt32:
+		return 0, fmt.Errorf("invalid TTL %s: must be at most %s", ttl, time.Duration(math.MaxInt32)*time.Second)
+	}
+	return int32(ttl / time.Second), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
+)
+
+func SVIDMintJWTCommand(config *rpcConfig) *cobra.Command {
+	impl := &svidMintJWT{config: config}
+	cmd := &cobra.Command{
+		Use:   "jwt",
+		Short: "Mints a JWT-SVID",
+		Long: `Mints a JWT-SVID via the SVID API and prints the token.
+
+With --inspect, the SPIFFE ID, audience and expiry of the token are printed
+to stderr, leaving only the token on stdout.`,
+		Args: cobra.NoArgs,
+		RunE: runOutErr(impl),
+	}
+	cmd.Flags().StringVarP(&impl.spiffeID, "spiffe-id", "", "", "SPIFFE ID of the JWT-SVID")
+	cmd.Flags().StringArrayVarP(&impl.audience, "audience", "", nil, "Audience of the JWT-SVID (repeatable)")
+	cmd.Flags().DurationVarP(&impl.ttl, "ttl", "", 0, "TTL of the JWT-SVID in whole seconds (defaults to the server's default)")
+	cmd.Flags().BoolVarP(&impl.inspect, "inspect", "", false, "Print the claims of the JWT-SVID to stderr")
+	_ = cmd.RegisterFlagCompletionFunc("spiffe-id", completeEntrySPIFFEIDs(config))
+	_ = cmd.MarkFlagRequired("spiffe-id")
+	return cmd
+}
+
+type svidMintJWT struct {
+	config   *rpcConfig
+	spiffeID string
+	audience []string
+	ttl      time.Duration
+	inspect  bool
+}
+
+func (cmd *svidMintJWT) Run(ctx context.Context, stderr io.Writer, args []string) ([]byte, error) {
+	id, err := parseSPIFFEID(cmd.spiffeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd.audience) == 0 {
+		return nil, errors.New("at least one audience is required")
+	}
+	ttl, err := ttlSeconds(cmd.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+	defer cmd.config.closeSource()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := svidv1.NewSVIDClient(conn).MintJWTSVID(ctx, &svidv1.MintJWTSVIDRequest{
+		Id:       id,
+		Audience: cmd.audience,
+		Ttl:      ttl,
+	})
+	if err != nil {
+		return nil, newRPCError("MintJWTSVID", err)
+	}
+	token := resp.Svid.GetToken()
+	if token == "" {
+		return nil, errors.New("MintJWTSVID returned no token")
+	}
+
+	if cmd.inspect {
+		claims, err := parseJWTSVIDClaims(token)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(stderr, "SPIFFE ID:  %s\n", claims.Subject)
+		fmt.Fprintf(stderr, "Audience:   %s\n", strings.Join(claims.Audience, ", "))
+		if claims.IssuedAt != nil {
+			fmt.Fprintf(stderr, "Issued at:  %s\n", claims.IssuedAt.Time().UTC().Format(time.RFC3339))
+		}
+		if claims.Expiry != nil {
+			expiresAt := claims.Expiry.Time()
+			fmt.Fprintf(stderr, "Expires at: %s (%s)\n", expiresAt.UTC().Format(time.RFC3339), formatExpiresIn(expiresAt, time.Now()))
+		}
+	}
+	return []byte(token + "\n"), nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
+)
+
+func SVIDMintX509Command(config *rpcConfig) *cobra.Command {
+	impl := &svidMintX509{config: config}
+	cmd := &cobra.Command{
+		Use:   "x509",
+		Short: "Mints an X509-SVID",
+		Long: `Mints an X509-SVID via the SVID API.
+
+A key and CSR are generated locally and the CSR is signed by the server. The
+certificate chain and key are written as PEM, in the form expected by
+--svid-path.`,
+		Args: cobra.NoArgs,
+		RunE: runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.spiffeID, "spiffe-id", "", "", "SPIFFE ID of the X509-SVID")
+	cmd.Flags().DurationVarP(&impl.ttl, "ttl", "", 0, "TTL of the X509-SVID in whole seconds (defaults to the server's default)")
+	cmd.Flags().StringVarP(&impl.write, "write", "", "", "File to write the X509-SVID and key to (instead of stdout)")
+	_ = cmd.RegisterFlagCompletionFunc("spiffe-id", completeEntrySPIFFEIDs(config))
+	_ = cmd.MarkFlagRequired("spiffe-id")
+	return cmd
+}
+
+type svidMintX509 struct {
+	config   *rpcConfig
+	spiffeID string
+	ttl      time.Duration
+	write    string
+}
+
+func (cmd *svidMintX509) Run(ctx context.Context, args []string) ([]byte, error) {
+	id, err := spiffeid.FromString(cmd.spiffeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID %q: %v", cmd.spiffeID, err)
+	}
+	ttl, err := ttlSeconds(cmd.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := createCSR(key, []*url.URL{id.URL()})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := cmd.config.withTimeout(ctx)
+	defer cancel()
+	defer cmd.config.closeSource()
+
+	conn, err := cmd.config.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := svidv1.NewSVIDClient(conn).MintX509SVID(ctx, &svidv1.MintX509SVIDRequest{
+		Csr: csr,
+		Ttl: ttl,
+	})
+	if err != nil {
+		return nil, newRPCError("MintX509SVID", err)
+	}
+	if len(resp.Svid.GetCertChain()) == 0 {
+		return nil, fmt.Errorf("MintX509SVID returned no certificates")
+	}
+
+	out := new(bytes.Buffer)
+	for _, cert := range resp.Svid.CertChain {
+		_ = pem.Encode(out, &pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	}
+	_ = pem.Encode(out, &pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+	if cmd.write == "" {
+		return out.Bytes(), nil
+	}
+
+	if err := writeFileAtomic(cmd.write, out.Bytes(), keyFileMode); err != nil {
+		return nil, fmt.Errorf("unable to write X509-SVID: %v", err)
+	}
+	expiresAt := time.Unix(resp.Svid.ExpiresAt, 0).UTC().Format(time.RFC3339)
+	return []byte(fmt.Sprintf("Wrote X509-SVID for %s (expires %s) to %s\n", id, expiresAt, cmd.write)), nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLSeconds(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		ttl     time.Duration
+		want    int32
+		wantErr string
+	}{
+		{name: "server default", ttl: 0, want: 0},
+		{name: "seconds", ttl: 90 * time.Second, want: 90},
+		{name: "hours", ttl: 24 * time.Hour, want: 86400},
+		{name: "maximum", ttl: 2147483647 * time.Second, want: 2147483647},
+		{name: "fraction of a second", ttl: 500 * time.Millisecond, wantErr: "must be a whole number of seconds"},
+		{name: "seconds and a fraction", ttl: 1500 * time.Millisecond, wantErr: "must be a whole number of seconds"},
+		{name: "negative", ttl: -time.Second, wantErr: "must not be negative"},
+		{name: "too large", ttl: 2147483648 * time.Second, wantErr: "must be at most"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ttlSeconds(tt.ttl)
+			assertErrorContains(t, err, tt.wantErr)
+			if err == nil && got != tt.want {
+				t.Fatalf("expected %d; got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package main
+
+import "github.com/spf13/cobra"
+
+func WorkloadCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "workload", Short: "High-level Workload API operations"}
+	cmd.AddCommand(WorkloadFetchCommand())
+	cmd.AddCommand(WorkloadWatchCommand())
+	cmd.AddCommand(WorkloadJWTCommand())
+	return cmd
+}
@@ -0,0 +1,9 @@
+package main
+
+import "github.com/spf13/cobra"
+
+func WorkloadFetchCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "fetch", Short: "Fetches SVIDs and bundles from the Workload API"}
+	cmd.AddCommand(WorkloadFetchX509Command())
+	return cmd
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+func WorkloadFetchX509Command() *cobra.Command {
+	impl := &workloadFetchX509{}
+	cmd := &cobra.Command{
+		Use:   "x509",
+		Short: "Fetches the X509-SVID and bundles and writes them as PEM files",
+		Long: `Fetches the default X509-SVID and bundles and writes them as PEM files:
+
+  svid.pem                      X509-SVID certificate chain
+  svid_key.pem                  X509-SVID private key (mode 0600)
+  bundle.pem                    bundle for the trust domain of the X509-SVID
+  federated_bundle_<TD>.pem     bundle for each federated trust domain
+
+The files are symlinks into a hidden data directory that is swapped in at
+once, so the certificate and key always match.`,
+		Args: cobra.NoArgs,
+		RunE: runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.workloadAPIAddr, "workload-api-addr", "", defaultWorkloadAPIAddr(), "Address to the Workload API socket (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	cmd.Flags().StringVarP(&impl.writeDir, "write-dir", "", "", "Directory to write the files to")
+	_ = cmd.MarkFlagRequired("write-dir")
+	return cmd
+}
+
+type workloadFetchX509 struct {
+	workloadAPIAddr string
+	writeDir        string
+}
+
+func (cmd *workloadFetchX509) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkWorkloadAPIAddr(cmd.workloadAPIAddr); err != nil {
+		return nil, err
+	}
+	x509Ctx, err := workloadapi.FetchX509Context(ctx, workloadapi.WithAddr(cmd.workloadAPIAddr))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch X509 context: %v", err)
+	}
+
+	paths, err := writeX509Files(cmd.writeDir, x509Ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to write files: %v", err)
+	}
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, "Fetched X509-SVID for %s\n", x509Ctx.DefaultSVID().ID)
+	for _, path := range paths {
+		fmt.Fprintf(out, "Wrote %s\n", path)
+	}
+	return out.Bytes(), nil
+}
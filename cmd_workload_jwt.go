@@ -0,0 +1,10 @@
+package main
+
+import "github.com/spf13/cobra"
+
+func WorkloadJWTCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "jwt", Short: "Fetches and validates JWT-SVIDs via the Workload API"}
+	cmd.AddCommand(WorkloadJWTFetchCommand())
+	cmd.AddCommand(WorkloadJWTValidateCommand())
+	return cmd
+}
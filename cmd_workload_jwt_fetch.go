@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+func WorkloadJWTFetchCommand() *cobra.Command {
+	impl := &workloadJWTFetch{}
+	cmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Fetches JWT-SVIDs and prints the token(s), one per line",
+		Args:  cobra.NoArgs,
+		RunE:  runOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.workloadAPIAddr, "workload-api-addr", "", defaultWorkloadAPIAddr(), "Address to the Workload API socket (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	cmd.Flags().StringSliceVarP(&impl.audience, "audience", "", nil, "Audience(s) of the JWT-SVID")
+	cmd.Flags().StringVarP(&impl.spiffeID, "spiffe-id", "", "", "SPIFFE ID of the JWT-SVID (defaults to all SVIDs available to the workload)")
+	_ = cmd.MarkFlagRequired("audience")
+	return cmd
+}
+
+type workloadJWTFetch struct {
+	workloadAPIAddr string
+	audience        []string
+	spiffeID        string
+}
+
+func (cmd *workloadJWTFetch) Run(ctx context.Context, args []string) ([]byte, error) {
+	if err := checkWorkloadAPIAddr(cmd.workloadAPIAddr); err != nil {
+		return nil, err
+	}
+	if len(cmd.audience) == 0 {
+		return nil, errors.New("at least one audience is required")
+	}
+	params := jwtsvid.Params{
+		Audience:       cmd.audience[0],
+		ExtraAudiences: cmd.audience[1:],
+	}
+	if cmd.spiffeID != "" {
+		id, err := spiffeid.FromString(cmd.spiffeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID: %v", err)
+		}
+		params.Subject = id
+	}
+
+	svids, err := workloadapi.FetchJWTSVIDs(ctx, params, workloadapi.WithAddr(cmd.workloadAPIAddr))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch JWT-SVID: %v", err)
+	}
+
+	out := new(bytes.Buffer)
+	for _, svid := range svids {
+		fmt.Fprintln(out, svid.Marshal())
+	}
+	return out.Bytes(), nil
+}
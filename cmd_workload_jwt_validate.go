@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+func WorkloadJWTValidateCommand() *cobra.Command {
+	impl := &workloadJWTValidate{}
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates a JWT-SVID (provided on stdin) and prints its claims",
+		Args:  cobra.NoArgs,
+		RunE:  runInOut(impl),
+	}
+	cmd.Flags().StringVarP(&impl.workloadAPIAddr, "workload-api-addr", "", defaultWorkloadAPIAddr(), "Address to the Workload API socket (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	cmd.Flags().StringVarP(&impl.audience, "audience", "", "", "Audience the JWT-SVID is expected to have")
+	_ = cmd.MarkFlagRequired("audience")
+	return cmd
+}
+
+type workloadJWTValidate struct {
+	workloadAPIAddr string
+	audience        string
+}
+
+func (cmd *workloadJWTValidate) Run(ctx context.Context, in []byte, args []string) ([]byte, error) {
+	if err := checkWorkloadAPIAddr(cmd.workloadAPIAddr); err != nil {
+		return nil, err
+	}
+	token := strings.TrimSpace(string(in))
+	if token == "" {
+		return nil, errors.New("stdin was empty. Did you forget to pipe?")
+	}
+
+	svid, err := workloadapi.ValidateJWTSVID(ctx, token, cmd.audience, workloadapi.WithAddr(cmd.workloadAPIAddr))
+	if err != nil {
+		return nil, fmt.Errorf("JWT-SVID is invalid: %v", err)
+	}
+
+	out, err := json.MarshalIndent(svid.Claims, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spiffe/go-spiffe/v2/bundle/jwtbundle"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+const jwtBundlePrefix = "jwt_bundle_"
+
+func WorkloadWatchCommand() *cobra.Command {
+	impl := &workloadWatch{}
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watches the Workload API and rewrites SVID and bundle files as they rotate",
+		Long: `Watches the Workload API and rewrites SVID and bundle files as they rotate.
+
+Files are written as by "workload fetch x509" (and, with --jwt-bundles,
+jwt_bundle_<TD>.json for each trust domain). The X509 files are symlinks into
+a data directory that is swapped in once per update, so the certificate and
+key always match. Files of trust domains that drop out of an update are
+removed. After each update the process given by --signal-pid is signaled
+and/or the --exec command is run, with its output sent to stderr. Each update
+is logged to stdout as a JSON object.
+
+The command runs until interrupted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			cobraCmd.SilenceUsage = true
+			return impl.Run(cobraCmd.Context(), cobraCmd.OutOrStdout(), cobraCmd.ErrOrStderr())
+		},
+	}
+	cmd.Flags().StringVarP(&impl.workloadAPIAddr, "workload-api-addr", "", defaultWorkloadAPIAddr(), "Address to the Workload API socket (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	cmd.Flags().StringVarP(&impl.writeDir, "write-dir", "", "", "Directory to write the files to")
+	cmd.Flags().BoolVarP(&impl.jwtBundles, "jwt-bundles", "", false, "Also watch JWT bundles and write them as JWKS files")
+	cmd.Flags().IntVarP(&impl.signalPID, "signal-pid", "", 0, "PID of a process to signal after each update")
+	cmd.Flags().StringVarP(&impl.signalName, "signal", "", "HUP", "Signal sent to --signal-pid (name or number)")
+	cmd.Flags().StringVarP(&impl.execCmd, "exec", "", "", "Command run (via sh -c) after each update")
+	_ = cmd.MarkFlagRequired("write-dir")
+	return cmd
+}
+
+type workloadWatch struct {
+	workloadAPIAddr string
+	writeDir        string
+	jwtBundles      bool
+	signalPID       int
+	signalName      string
+	execCmd         string
+
+	ctx    context.Context
+	mu     sync.Mutex
+	log    *json.Encoder
+	stderr io.Writer
+	sig    os.Signal
+}
+
+// watchEvent is logged for each update or error.
+type watchEvent struct {
+	Time         time.Time `json:"time"`
+	Event        string    `json:"event"`
+	SPIFFEID     string    `json:"spiffe_id,omitempty"`
+	Serial       string    `json:"serial,omitempty"`
+	ExpiresAt    string    `json:"expires_at,omitempty"`
+	TrustDomains []string  `json:"trust_domains,omitempty"`
+	Files        []string  `json:"files,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+func (cmd *workloadWatch) Run(ctx context.Context, out, stderr io.Writer) error {
+	if err := checkWorkloadAPIAddr(cmd.workloadAPIAddr); err != nil {
+		return err
+	}
+	if cmd.signalPID != 0 {
+		sig, err := parseSignal(cmd.signalName)
+		if err != nil {
+			return err
+		}
+		cmd.sig = sig
+	}
+	cmd.log = json.NewEncoder(out)
+	cmd.stderr = stderr
+
+	// Watching outlives the command timeout. It runs until interrupted.
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cmd.ctx = ctx
+
+	client, err := workloadapi.New(ctx, workloadapi.WithAddr(cmd.workloadAPIAddr))
+	if err != nil {
+		return fmt.Errorf("unable to create Workload API client: %v", err)
+	}
+	defer client.Close()
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- client.WatchX509Context(ctx, x509Watcher{cmd})
+	}()
+	if cmd.jwtBundles {
+		go func() {
+			errCh <- client.WatchJWTBundles(ctx, jwtBundleWatcher{cmd})
+		}()
+	}
+
+	err = <-errCh
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func (cmd *workloadWatch) onX509ContextUpdate(x509Ctx *workloadapi.X509Context) {
+	files, err := writeX509Files(cmd.writeDir, x509Ctx)
+	if err != nil {
+		cmd.logError(fmt.Errorf("unable to write X509 files: %v", err))
+		return
+	}
+	svid := x509Ctx.DefaultSVID()
+	leaf := svid.Certificates[0]
+	cmd.logEvent(watchEvent{
+		Event:     "x509_svid_updated",
+		SPIFFEID:  svid.ID.String(),
+		Serial:    leaf.SerialNumber.String(),
+		ExpiresAt: leaf.NotAfter.UTC().Format(time.RFC3339),
+		Files:     files,
+	})
+	cmd.runHooks()
+}
+
+func (cmd *workloadWatch) onJWTBundlesUpdate(bundles *jwtbundle.Set) {
+	var files []string
+	var trustDomains []string
+	for _, bundle := range bundles.Bundles() {
+		jwks, err := bundle.Marshal()
+		if err != nil {
+			cmd.logError(fmt.Errorf("unable to marshal JWT bundle for %q: %v", bundle.TrustDomain(), err))
+			return
+		}
+		path := filepath.Join(cmd.writeDir, jwtBundlePrefix+bundle.TrustDomain().String()+".json")
+		if err := os.MkdirAll(cmd.writeDir, 0755); err != nil {
+			cmd.logError(err)
+			return
+		}
+		if err := writeFileAtomic(path, jwks, certFileMode); err != nil {
+			cmd.logError(fmt.Errorf("unable to write JWT bundle: %v", err))
+			return
+		}
+		files = append(files, path)
+		trustDomains = append(trustDomains, bundle.TrustDomain().String())
+	}
+	if err := removeStaleFiles(cmd.writeDir, jwtBundlePrefix+"*.json", files); err != nil {
+		cmd.logError(fmt.Errorf("unable to remove JWT bundle: %v", err))
+		return
+	}
+	cmd.logEvent(watchEvent{
+		Event:        "jwt_bundles_updated",
+		TrustDomains: trustDomains,
+		Files:        files,
+	})
+	cmd.runHooks()
+}
+
+func (cmd *workloadWatch) onWatchError(what string, err error) {
+	// Errors are expected when the watch is stopped.
+	if cmd.ctx.Err() != nil {
+		return
+	}
+	cmd.logError(fmt.Errorf("%s watch: %v", what, err))
+}
+
+func (cmd *workloadWatch) runHooks() {
+	if cmd.sig != nil {
+		if err := signalProcess(cmd.signalPID, cmd.sig); err != nil {
+			cmd.logError(fmt.Errorf("unable to signal process %d: %v", cmd.signalPID, err))
+		}
+	}
+	if cmd.execCmd != "" {
+		// Hook output goes to stderr so stdout only carries the event log.
+		c := exec.Command("sh", "-c", cmd.execCmd)
+		c.Stdout = cmd.stderr
+		c.Stderr = cmd.stderr
+		if err := c.Run(); err != nil {
+			cmd.logError(fmt.Errorf("command %q failed: %v", cmd.execCmd, err))
+		}
+	}
+}
+
+func (cmd *workloadWatch) logEvent(event watchEvent) {
+	cmd.mu.Lock()
+	defer cmd.mu.Unlock()
+	event.Time = time.Now().UTC()
+	_ = cmd.log.Encode(event)
+}
+
+func (cmd *workloadWatch) logError(err error) {
+	cmd.logEvent(watchEvent{Event: "error", Error: err.Error()})
+}
+
+type x509Watcher struct {
+	cmd *workloadWatch
+}
+
+func (w x509Watcher) OnX509ContextUpdate(x509Ctx *workloadapi.X509Context) {
+	w.cmd.onX509ContextUpdate(x509Ctx)
+}
+
+func (w x509Watcher) OnX509ContextWatchError(err error) {
+	w.cmd.onWatchError("X509 context", err)
+}
+
+type jwtBundleWatcher struct {
+	cmd *workloadWatch
+}
+
+func (w jwtBundleWatcher) OnJWTBundlesUpdate(bundles *jwtbundle.Set) {
+	w.cmd.onJWTBundlesUpdate(bundles)
+}
+
+func (w jwtBundleWatcher) OnJWTBundlesWatchError(err error) {
+	w.cmd.onWatchError("JWT bundle", err)
+}
+
+var signalNames = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+// parseSignal parses a signal name (e.g. HUP or SIGHUP) or number.
+func parseSignal(s string) (os.Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return syscall.Signal(n), nil
+	}
+	if sig, ok := signalNames[strings.TrimPrefix(strings.ToUpper(s), "SIG")]; ok {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("unknown signal %q", s)
+}
+
+func signalProcess(pid int, sig os.Signal) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Signal(sig)
+}
+
+// removeStaleFiles removes the files in dir matching pattern that are not in
+// keep.
+func removeStaleFiles(dir, pattern string, keep []string) error {
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return err
+	}
+	for _, path := range matches {
+		if !slices.Contains(keep, path) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+const (
+	// spiffeEndpointSocketEnv is the environment variable defined by the
+	// SPIFFE Workload Endpoint specification for the Workload API address.
+	spiffeEndpointSocketEnv = "SPIFFE_ENDPOINT_SOCKET"
+	// spireServerSocketEnv is the environment variable used to locate the
+	// SPIRE Server API socket.
+	spireServerSocketEnv = "SPIRE_SERVER_SOCKET"
+)
+
+func defaultWorkloadAPIAddr() string {
+	return envSocketAddr(spiffeEndpointSocketEnv, "unix:///tmp/spire-agent/public/api.sock")
+}
+
+func defaultServerUDSAddr() string {
+	return envSocketAddr(spireServerSocketEnv, "unix:///tmp/spire-server/private/api.sock")
+}
+
+// envSocketAddr returns the socket address in the environment variable, or
+// the fallback if unset. A bare absolute path is treated as a unix address.
+func envSocketAddr(name, fallback string) string {
+	addr := os.Getenv(name)
+	switch {
+	case addr == "":
+		return fallback
+	case filepath.IsAbs(addr):
+		return "unix://" + addr
+	default:
+		return addr
+	}
+}
+
+// checkSocketAddr validates the scheme of a socket address (unix:,
+// unix-abstract: or tcp:) and, for unix addresses, that the socket exists,
+// so that a missing socket is reported right away instead of the dial
+// blocking until the command times out.
+func checkSocketAddr(addr string) error {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return fmt.Errorf("invalid socket address %q: %v", addr, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		if u.Host != "" {
+			return fmt.Errorf("invalid socket address %q: unix addresses must be of the form unix:///absolute/path", addr)
+		}
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			return fmt.Errorf("socket %s does not exist", path)
+		case err != nil:
+			return fmt.Errorf("unable to access socket %s: %v", path, err)
+		case info.Mode()&os.ModeSocket == 0:
+			return fmt.Errorf("%s is not a socket", path)
+		}
+		return nil
+	case "unix-abstract":
+		return nil
+	case "tcp":
+		if u.Host == "" {
+			return fmt.Errorf("invalid socket address %q: tcp addresses must be of the form tcp://host:port", addr)
+		}
+		return nil
+	case "":
+		return fmt.Errorf("invalid socket address %q: missing scheme (expected unix:, unix-abstract: or tcp:)", addr)
+	default:
+		return fmt.Errorf("invalid socket address %q: unsupported scheme %q (expected unix:, unix-abstract: or tcp:)", addr, u.Scheme)
+	}
+}
+
+// checkWorkloadAPIAddr validates a Workload API address. In addition to the
+// checks of checkSocketAddr, the address must be one the go-spiffe Workload
+// API client can dial, which excludes unix-abstract addresses and tcp
+// addresses with a hostname instead of an IP.
+func checkWorkloadAPIAddr(addr string) error {
+	if err := checkSocketAddr(addr); err != nil {
+		return err
+	}
+	if u, _ := url.Parse(addr); u.Scheme == "unix-abstract" {
+		return fmt.Errorf("invalid Workload API address %q: unix-abstract addresses are not supported", addr)
+	}
+	if err := workloadapi.ValidateAddress(addr); err != nil {
+		return fmt.Errorf("invalid Workload API address %q: %v", addr, err)
+	}
+	return nil
+}
+
+// socketTarget validates a socket address and returns the equivalent gRPC
+// dial target.
+func socketTarget(addr string) (string, error) {
+	if err := checkSocketAddr(addr); err != nil {
+		return "", err
+	}
+	if u, _ := url.Parse(addr); u.Scheme == "tcp" {
+		return "dns:///" + u.Host, nil
+	}
+	return addr, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSocketAddr(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "api.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	filePath := filepath.Join(dir, "file")
+	if err := os.WriteFile(filePath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	missingPath := filepath.Join(dir, "missing.sock")
+
+	for _, tt := range []struct {
+		name               string
+		addr               string
+		wantErr            string
+		wantWorkloadAPIErr string
+	}{
+		{
+			name: "unix",
+			addr: "unix://" + socketPath,
+		},
+		{
+			name: "unix opaque",
+			addr: "unix:" + socketPath,
+		},
+		{
+			name:               "unix missing",
+			addr:               "unix://" + missingPath,
+			wantErr:            "socket " + missingPath + " does not exist",
+			wantWorkloadAPIErr: "socket " + missingPath + " does not exist",
+		},
+		{
+			name:               "unix not a socket",
+			addr:               "unix://" + filePath,
+			wantErr:            filePath + " is not a socket",
+			wantWorkloadAPIErr: filePath + " is not a socket",
+		},
+		{
+			name:               "unix with host",
+			addr:               "unix://host" + socketPath,
+			wantErr:            "unix addresses must be of the form unix:///absolute/path",
+			wantWorkloadAPIErr: "unix addresses must be of the form unix:///absolute/path",
+		},
+		{
+			name:               "unix-abstract",
+			addr:               "unix-abstract:spire",
+			wantWorkloadAPIErr: "unix-abstract addresses are not supported",
+		},
+		{
+			name: "tcp",
+			addr: "tcp://127.0.0.1:8081",
+		},
+		{
+			name:               "tcp hostname",
+			addr:               "tcp://localhost:8081",
+			wantWorkloadAPIErr: "host component must be an IP:port",
+		},
+		{
+			name:               "tcp without host",
+			addr:               "tcp:8081",
+			wantErr:            "tcp addresses must be of the form tcp://host:port",
+			wantWorkloadAPIErr: "tcp addresses must be of the form tcp://host:port",
+		},
+		{
+			name:               "missing scheme",
+			addr:               socketPath,
+			wantErr:            "missing scheme (expected unix:, unix-abstract: or tcp:)",
+			wantWorkloadAPIErr: "missing scheme (expected unix:, unix-abstract: or tcp:)",
+		},
+		{
+			name:               "unsupported scheme",
+			addr:               "http://localhost:8081",
+			wantErr:            `unsupported scheme "http" (expected unix:, unix-abstract: or tcp:)`,
+			wantWorkloadAPIErr: `unsupported scheme "http" (expected unix:, unix-abstract: or tcp:)`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assertErrorContains(t, checkSocketAddr(tt.addr), tt.wantErr)
+			assertErrorContains(t, checkWorkloadAPIAddr(tt.addr), tt.wantWorkloadAPIErr)
+		})
+	}
+}
+
+func TestSocketTarget(t *testing.T) {
+	target, err := socketTarget("tcp://spire-server:8081")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "dns:///spire-server:8081" {
+		t.Fatalf("expected dns:///spire-server:8081; got %s", target)
+	}
+}
+
+func assertErrorContains(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case want != "" && err == nil:
+		t.Fatalf("expected error containing %q", want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Fatalf("expected error containing %q; got %v", want, err)
+	}
+}
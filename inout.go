@@ -11,6 +11,10 @@ type outCommand interface {
 	Run(ctx context.Context, args []string) ([]byte, error)
 }
 
+type outErrCommand interface {
+	Run(ctx context.Context, stderr io.Writer, args []string) ([]byte, error)
+}
+
 type inOutCommand interface {
 	Run(ctx context.Context, in []byte, args []string) ([]byte, error)
 }
@@ -20,13 +24,26 @@ func runOut(cmd outCommand) func(cobraCmd *cobra.Command, args []string) error {
 		cobraCmd.SilenceUsage = true
 
 		out, err := cmd.Run(cobraCmd.Context(), args)
-		if err != nil {
-			return err
+		// Commands may return output alongside an error (e.g. a report of
+		// which steps failed) which is still useful to the caller.
+		if _, werr := cobraCmd.OutOrStdout().Write(out); werr != nil && err == nil {
+			return werr
 		}
-		if _, err := cobraCmd.OutOrStdout().Write(out); err != nil {
-			return err
+		return err
+	}
+}
+
+// runOutErr is like runOut for commands that also write diagnostics (e.g.
+// the statuses of failed batch results) to stderr.
+func runOutErr(cmd outErrCommand) func(cobraCmd *cobra.Command, args []string) error {
+	return func(cobraCmd *cobra.Command, args []string) error {
+		cobraCmd.SilenceUsage = true
+
+		out, err := cmd.Run(cobraCmd.Context(), cobraCmd.ErrOrStderr(), args)
+		if _, werr := cobraCmd.OutOrStdout().Write(out); werr != nil && err == nil {
+			return werr
 		}
-		return nil
+		return err
 	}
 }
 
@@ -39,13 +56,12 @@ func runInOut(cmd inOutCommand) func(cobraCmd *cobra.Command, args []string) err
 			return err
 		}
 		out, err := cmd.Run(cobraCmd.Context(), in, args)
-		if err != nil {
-			return err
-		}
-		if _, err := cobraCmd.OutOrStdout().Write(out); err != nil {
-			return err
+		// Commands may return output alongside an error (e.g. a batch
+		// response with failed results) which is still useful to the caller.
+		if _, werr := cobraCmd.OutOrStdout().Write(out); werr != nil && err == nil {
+			return werr
 		}
-		return nil
+		return err
 	}
 }
 
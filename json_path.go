@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalPath evaluates a jq/JSONPath-like path expression against a decoded
+// JSON value. Supported syntax:
+//
+//	.field or $.field       object field
+//	["field"]               object field (quoted)
+//	[N]                     array element (negative indexes count from the end)
+//	[] or [*]               every array element
+//
+// Object fields may be given by their protobuf name (e.g. spiffe_id) even
+// when the JSON uses the lowerCamelCase name (e.g. spiffeId).
+func evalPath(v interface{}, expr string) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, "$")
+	if expr == "." || expr == "" {
+		return v, nil
+	}
+
+	values := []interface{}{v}
+	fanout := false
+
+	for expr != "" {
+		var next []interface{}
+		switch {
+		case strings.HasPrefix(expr, "[]") || strings.HasPrefix(expr, "[*]"):
+			if strings.HasPrefix(expr, "[]") {
+				expr = expr[2:]
+			} else {
+				expr = expr[3:]
+			}
+			for _, value := range values {
+				list, ok := value.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot iterate over %s", jsonTypeName(value))
+				}
+				next = append(next, list...)
+			}
+			fanout = true
+		case strings.HasPrefix(expr, `["`):
+			end := strings.Index(expr, `"]`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated field in path %q", expr)
+			}
+			name := expr[2:end]
+			expr = expr[end+2:]
+			for _, value := range values {
+				fv, err := pathField(value, name)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, fv)
+			}
+		case strings.HasPrefix(expr, "["):
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in path %q", expr)
+			}
+			index, err := strconv.Atoi(expr[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in path", expr[1:end])
+			}
+			expr = expr[end+1:]
+			for _, value := range values {
+				list, ok := value.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot index %s", jsonTypeName(value))
+				}
+				i := index
+				if i < 0 {
+					i += len(list)
+				}
+				if i < 0 || i >= len(list) {
+					return nil, fmt.Errorf("index %d out of range", index)
+				}
+				next = append(next, list[i])
+			}
+		case strings.HasPrefix(expr, "."):
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			name := expr[:end]
+			expr = expr[end:]
+			if name == "" {
+				continue
+			}
+			for _, value := range values {
+				fv, err := pathField(value, name)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, fv)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in path", expr)
+		}
+		values = next
+	}
+
+	if fanout {
+		return values, nil
+	}
+	return values[0], nil
+}
+
+func pathField(v interface{}, name string) (interface{}, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		if v == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot get field %q of %s", name, jsonTypeName(v))
+	}
+	if fv, ok := obj[name]; ok {
+		return fv, nil
+	}
+	return obj[lowerCamelCase(name)], nil
+}
+
+// lowerCamelCase converts a protobuf field name into its JSON name.
+func lowerCamelCase(s string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range s {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		return "number"
+	}
+}
+
+// decodeJSON decodes JSON into generic values, preserving numbers as
+// json.Number so that 64-bit integers survive a round trip.
+func decodeJSON(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
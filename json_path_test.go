@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEvalPath(t *testing.T) {
+	doc, err := decodeJSON([]byte(`{
+		"entries": [
+			{"id": "a", "spiffeId": {"trustDomain": "example.org", "path": "/one"}, "ttl": 3600},
+			{"id": "b", "spiffeId": {"trustDomain": "example.org", "path": "/two"}}
+		],
+		"next.page": "token"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		expr    string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "identity", expr: ".", want: doc},
+		{name: "empty", expr: "", want: doc},
+		{name: "index", expr: ".entries[0].id", want: "a"},
+		{name: "negative index", expr: ".entries[-1].id", want: "b"},
+		{name: "dollar prefix", expr: "$.entries[1].id", want: "b"},
+		{name: "fanout", expr: ".entries[].id", want: []interface{}{"a", "b"}},
+		{name: "fanout star", expr: ".entries[*].id", want: []interface{}{"a", "b"}},
+		{name: "proto name", expr: ".entries[0].spiffe_id.trust_domain", want: "example.org"},
+		{name: "quoted field", expr: `["next.page"]`, want: "token"},
+		{name: "missing field", expr: ".entries[1].ttl", want: nil},
+		{name: "field of missing field", expr: ".missing.field", want: nil},
+		{name: "number", expr: ".entries[0].ttl", want: json.Number("3600")},
+		{name: "index out of range", expr: ".entries[2]", wantErr: "index 2 out of range"},
+		{name: "invalid index", expr: ".entries[x]", wantErr: `invalid index "x" in path`},
+		{name: "unterminated index", expr: ".entries[0", wantErr: `unterminated index in path "[0"`},
+		{name: "unterminated field", expr: `["next`, wantErr: `unterminated field in path "[\"next"`},
+		{name: "iterate object", expr: "[]", wantErr: "cannot iterate over object"},
+		{name: "index object", expr: "[0]", wantErr: "cannot index object"},
+		{name: "field of string", expr: ".entries[0].id.foo", wantErr: `cannot get field "foo" of string`},
+		{name: "unexpected", expr: "entries", wantErr: `unexpected "entries" in path`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evalPath(doc, tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q; got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %#v; got %#v", tt.want, got)
+			}
+		})
+	}
+}
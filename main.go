@@ -21,6 +21,18 @@ func main() {
 	cmd.AddCommand(GenerateCommand())
 	cmd.AddCommand(RPCCommand())
 	cmd.AddCommand(DumpCommand())
+	cmd.AddCommand(ShellCommand())
+	cmd.AddCommand(RunCommand())
+	cmd.AddCommand(WorkloadCommand())
+	cmd.AddCommand(EntryCommand())
+	cmd.AddCommand(AgentCommand())
+	cmd.AddCommand(FederationCommand())
+	cmd.AddCommand(ServeCommand())
+	cmd.AddCommand(BundleCommand())
+	cmd.AddCommand(ExportCommand())
+	cmd.AddCommand(ImportCommand())
+	cmd.AddCommand(SVIDCommand())
+	registerBytesFormatCompletions(cmd)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
 	defer cancel()
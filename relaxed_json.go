@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// relaxJSON converts a relaxed, JSON5-style document into strict JSON. The
+// following relaxations are supported:
+//   - comments (// and /* */)
+//   - single quoted strings
+//   - unquoted object keys (and bare identifiers, which are quoted)
+//   - trailing commas in objects and arrays
+//
+// Strict JSON passes through unchanged apart from comment removal and
+// whitespace around commas.
+func relaxJSON(in []byte) ([]byte, error) {
+	out := new(bytes.Buffer)
+	pendingComma := false
+	flushComma := func() {
+		if pendingComma {
+			out.WriteByte(',')
+			pendingComma = false
+		}
+	}
+
+	for i := 0; i < len(in); {
+		c := in[i]
+		switch {
+		case c == '/' && i+1 < len(in) && in[i+1] == '/':
+			end := bytes.IndexByte(in[i:], '\n')
+			if end < 0 {
+				i = len(in)
+			} else {
+				i += end
+			}
+		case c == '/' && i+1 < len(in) && in[i+1] == '*':
+			end := bytes.Index(in[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += end + 4
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			out.WriteByte(c)
+			i++
+		case c == ',':
+			flushComma()
+			pendingComma = true
+			i++
+		case c == '}' || c == ']':
+			// Drop any trailing comma.
+			pendingComma = false
+			out.WriteByte(c)
+			i++
+		case c == '"' || c == '\'':
+			flushComma()
+			n, err := writeRelaxedString(out, in[i:])
+			if err != nil {
+				return nil, err
+			}
+			i += n
+		case c == '-' || (c >= '0' && c <= '9'):
+			flushComma()
+			j := i + 1
+			for j < len(in) && (isIdentPart(in[j]) || in[j] == '.' || in[j] == '-' || in[j] == '+') {
+				j++
+			}
+			out.Write(in[i:j])
+			i = j
+		case isIdentStart(c):
+			flushComma()
+			j := i + 1
+			for j < len(in) && isIdentPart(in[j]) {
+				j++
+			}
+			switch ident := string(in[i:j]); ident {
+			case "true", "false", "null":
+				out.WriteString(ident)
+			default:
+				fmt.Fprintf(out, "%q", ident)
+			}
+			i = j
+		default:
+			flushComma()
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// writeRelaxedString writes the double or single quoted string at the start
+// of in as a double quoted string. It returns the number of bytes consumed.
+func writeRelaxedString(out *bytes.Buffer, in []byte) (int, error) {
+	quote := in[0]
+	out.WriteByte('"')
+	for i := 1; i < len(in); i++ {
+		switch c := in[i]; {
+		case c == quote:
+			out.WriteByte('"')
+			return i + 1, nil
+		case c == '\\' && i+1 < len(in):
+			i++
+			if in[i] == '\'' {
+				out.WriteByte('\'')
+			} else {
+				out.WriteByte('\\')
+				out.WriteByte(in[i])
+			}
+		case c == '"':
+			out.WriteString(`\"`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return 0, errors.New("unterminated string")
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
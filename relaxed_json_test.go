@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRelaxJSON(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		in      string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "strict JSON",
+			in:   `{"id": "abc", "ttl": 3600, "admin": true, "hint": null}`,
+			want: `{"id": "abc", "ttl": 3600, "admin": true, "hint": null}`,
+		},
+		{
+			name: "unquoted keys",
+			in:   `{filter: {by_spiffe_id: {trust_domain: "example.org"}}}`,
+			want: `{"filter": {"by_spiffe_id": {"trust_domain": "example.org"}}}`,
+		},
+		{
+			name: "single quoted strings",
+			in:   `{path: '/foo'}`,
+			want: `{"path": "/foo"}`,
+		},
+		{
+			name: "double quotes in single quoted string",
+			in:   `{hint: 'say "hi"'}`,
+			want: `{"hint": "say \"hi\""}`,
+		},
+		{
+			name: "escaped single quote",
+			in:   `{hint: 'it\'s'}`,
+			want: `{"hint": "it's"}`,
+		},
+		{
+			name: "other escapes are kept",
+			in:   `{hint: 'a\nb\\'}`,
+			want: `{"hint": "a\nb\\"}`,
+		},
+		{
+			name: "trailing commas",
+			in:   `{ids: ['a', 'b',], page_size: 10,}`,
+			want: `{"ids": ["a", "b"], "page_size": 10}`,
+		},
+		{
+			name: "strings containing relaxed syntax",
+			in:   `{value: 'a, b}', other: "x: 'y', // z"}`,
+			want: `{"value": "a, b}", "other": "x: 'y', // z"}`,
+		},
+		{
+			name: "comments",
+			in:   "{\n  // the entry\n  id: 'abc', /* inline */ ttl: -1.5e3\n}",
+			want: "{\n  \n  \"id\": \"abc\",  \"ttl\": -1.5e3\n}",
+		},
+		{
+			name: "bare identifier values are quoted",
+			in:   `{match: MATCH_EXACT, ok: false}`,
+			want: `{"match": "MATCH_EXACT", "ok": false}`,
+		},
+		{
+			name:    "unterminated string",
+			in:      `{id: 'abc}`,
+			wantErr: "unterminated string",
+		},
+		{
+			name:    "unterminated comment",
+			in:      `{id: 'abc' /* oops}`,
+			wantErr: "unterminated comment",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := relaxJSON([]byte(tt.in))
+			assertErrorContains(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+			// Commas are moved past whitespace, so outputs are compared
+			// compacted (which also checks that they are strict JSON).
+			compact := func(b []byte) string {
+				out := new(bytes.Buffer)
+				if err := json.Compact(out, b); err != nil {
+					t.Fatalf("invalid JSON %s: %v", b, err)
+				}
+				return out.String()
+			}
+			if compact(got) != compact([]byte(tt.want)) {
+				t.Fatalf("expected %s; got %s", tt.want, got)
+			}
+		})
+	}
+}
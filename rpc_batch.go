@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	typesv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var statusFullName = (&typesv1.Status{}).ProtoReflect().Descriptor().FullName()
+
+// checkBatchResults inspects the per-item statuses of a batch response (i.e.
+// any message with a repeated "results" field whose elements carry a
+// "status" field). A line is written to w for each item. An error is returned
+// if any item failed. Responses that are not batch responses are ignored.
+func checkBatchResults(w io.Writer, methodName string, m proto.Message) error {
+	mr := m.ProtoReflect()
+	fd := mr.Descriptor().Fields().ByName("results")
+	if fd == nil || !fd.IsList() || fd.Message() == nil {
+		return nil
+	}
+	statusFd := fd.Message().Fields().ByName("status")
+	if statusFd == nil || statusFd.Message() == nil || statusFd.Message().FullName() != statusFullName {
+		return nil
+	}
+
+	results := mr.Get(fd).List()
+	failed := 0
+	for i := 0; i < results.Len(); i++ {
+		result := results.Get(i).Message()
+		code := codes.Unknown
+		message := "result is missing status"
+		if result.Has(statusFd) {
+			st := result.Get(statusFd).Message().Interface().(*typesv1.Status)
+			code = codes.Code(st.Code)
+			message = st.Message
+		}
+		if code != codes.OK {
+			failed++
+		}
+
+		line := fmt.Sprintf("results[%d]", i)
+		if label := batchResultLabel(result); label != "" {
+			line += " " + label
+		}
+		line += ": " + code.String()
+		if message != "" {
+			line += ": " + message
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("rpc %s: %d of %d batch results failed", methodName, failed, results.Len())
+	}
+	return nil
+}
+
+// batchResultLabel returns a short identifier for a batch result, taken from
+// the result itself (e.g. the id of a deleted entry) or from the message it
+// carries (e.g. the id of a created entry), if either has one.
+func batchResultLabel(result protoreflect.Message) string {
+	if label := identifierField(result); label != "" {
+		return label
+	}
+	fields := result.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() || fd.Message().FullName() == statusFullName || !result.Has(fd) {
+			continue
+		}
+		if label := identifierField(result.Get(fd).Message()); label != "" {
+			return label
+		}
+	}
+	return ""
+}
+
+func identifierField(m protoreflect.Message) string {
+	for _, name := range []protoreflect.Name{"id", "trust_domain"} {
+		fd := m.Descriptor().Fields().ByName(name)
+		if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+			continue
+		}
+		if s := m.Get(fd).String(); s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// certFields are the names of bytes fields known to hold DER encoded
+// certificates (or concatenations of them).
+var certFields = map[protoreflect.Name]bool{
+	"asn1":              true,
+	"cert_chain":        true,
+	"ca_cert_chain":     true,
+	"x509_authorities":  true,
+	"x509_svid":         true,
+	"bundle":            true,
+	"bundles":           true,
+	"federated_bundles": true,
+	"ca_certificates":   true,
+}
+
+// publicKeyFields are the names of bytes fields known to hold PKIX encoded
+// public keys.
+var publicKeyFields = map[protoreflect.Name]bool{
+	"public_key": true,
+}
+
+// decodeCerts replaces the base64 values of certificate and public key
+// fields in v, the decoded protojson of m, with summaries of their contents.
+// Values that cannot be parsed (e.g. the JWKS of JWT bundles) are left as is.
+func decodeCerts(m protoreflect.Message, v interface{}, useProtoNames bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		key := fd.JSONName()
+		if useProtoNames {
+			key = string(fd.Name())
+		}
+		jsonValue, ok := obj[key]
+		if !ok {
+			return true
+		}
+
+		switch {
+		case fd.IsMap():
+			// Map fields are of message kind (their entries), so maps of
+			// bytes (e.g. federated_bundles) are told apart by their values.
+			entries, ok := jsonValue.(map[string]interface{})
+			if !ok {
+				return true
+			}
+			switch mv := fd.MapValue(); {
+			case mv.Kind() == protoreflect.BytesKind:
+				if !certFields[fd.Name()] && !publicKeyFields[fd.Name()] {
+					return true
+				}
+				for k := range entries {
+					if s, ok := entries[k].(string); ok {
+						entries[k] = summarizeDER(fd.Name(), s)
+					}
+				}
+			case mv.Message() != nil && !strings.HasPrefix(string(mv.Message().FullName()), "google.protobuf."):
+				value.Map().Range(func(mk protoreflect.MapKey, mv protoreflect.Value) bool {
+					decodeCerts(mv.Message(), entries[mk.String()], useProtoNames)
+					return true
+				})
+			}
+		case fd.Kind() == protoreflect.BytesKind:
+			if !certFields[fd.Name()] && !publicKeyFields[fd.Name()] {
+				return true
+			}
+			switch jv := jsonValue.(type) {
+			case string:
+				obj[key] = summarizeDER(fd.Name(), jv)
+			case []interface{}:
+				for i := range jv {
+					if s, ok := jv[i].(string); ok {
+						jv[i] = summarizeDER(fd.Name(), s)
+					}
+				}
+			}
+		case fd.Message() != nil && !strings.HasPrefix(string(fd.Message().FullName()), "google.protobuf."):
+			if fd.IsList() {
+				list, ok := jsonValue.([]interface{})
+				if !ok {
+					return true
+				}
+				for i := 0; i < value.List().Len() && i < len(list); i++ {
+					decodeCerts(value.List().Get(i).Message(), list[i], useProtoNames)
+				}
+				return true
+			}
+			decodeCerts(value.Message(), jsonValue, useProtoNames)
+		}
+		return true
+	})
+}
+
+// summarizeDER summarizes the base64 encoded DER value of a field. A single
+// certificate is summarized as an object and several as an array.
+func summarizeDER(field protoreflect.Name, b64 string) interface{} {
+	der, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return b64
+	}
+	if publicKeyFields[field] {
+		if _, err := x509.ParsePKIXPublicKey(der); err != nil {
+			return b64
+		}
+		return map[string]interface{}{
+			"type":              describePublicKey(der),
+			"sha256Fingerprint": fingerprint(der),
+		}
+	}
+
+	certs, err := x509.ParseCertificates(der)
+	if err != nil || len(certs) == 0 {
+		return b64
+	}
+	summaries := make([]interface{}, 0, len(certs))
+	for _, cert := range certs {
+		summaries = append(summaries, summarizeCertificate(cert))
+	}
+	if len(summaries) == 1 {
+		return summaries[0]
+	}
+	return summaries
+}
+
+func summarizeCertificate(cert *x509.Certificate) map[string]interface{} {
+	summary := map[string]interface{}{
+		"subject":           cert.Subject.String(),
+		"issuer":            cert.Issuer.String(),
+		"serialNumber":      cert.SerialNumber.String(),
+		"notBefore":         cert.NotBefore.UTC().Format(time.RFC3339),
+		"notAfter":          cert.NotAfter.UTC().Format(time.RFC3339),
+		"isCA":              cert.IsCA,
+		"publicKey":         describePublicKey(cert.RawSubjectPublicKeyInfo),
+		"sha256Fingerprint": fingerprint(cert.Raw),
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			summary["spiffeId"] = uri.String()
+			break
+		}
+	}
+	if len(cert.DNSNames) > 0 {
+		dnsNames := make([]interface{}, 0, len(cert.DNSNames))
+		for _, dnsName := range cert.DNSNames {
+			dnsNames = append(dnsNames, dnsName)
+		}
+		summary["dnsNames"] = dnsNames
+	}
+	if len(cert.SubjectKeyId) > 0 {
+		summary["subjectKeyId"] = formatKeyID(cert.SubjectKeyId)
+	}
+	return summary
+}
+
+// fingerprint returns the SHA-256 fingerprint of data as colon separated hex.
+func fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return formatKeyID(sum[:])
+}
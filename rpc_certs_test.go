@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeCerts(t *testing.T) {
+	caDER := createTestCertificate(t, "CA", "")
+	svidDER := createTestCertificate(t, "", "spiffe://example.org/workload")
+	federatedDER := createTestCertificate(t, "Federated CA", "")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := []byte(`{"keys":[]}`)
+
+	for _, tt := range []struct {
+		name          string
+		m             proto.Message
+		useProtoNames bool
+		want          map[string]interface{}
+	}{
+		{
+			name: "X509SVIDResponse",
+			m: &workload.X509SVIDResponse{
+				Svids: []*workload.X509SVID{{
+					SpiffeId: "spiffe://example.org/workload",
+					X509Svid: append(svidDER, caDER...),
+					Bundle:   caDER,
+				}},
+				FederatedBundles: map[string][]byte{
+					"spiffe://federated.test": federatedDER,
+				},
+			},
+			want: map[string]interface{}{
+				"svids": []interface{}{map[string]interface{}{
+					"spiffeId": "spiffe://example.org/workload",
+					"x509Svid": []interface{}{summary(t, svidDER), summary(t, caDER)},
+					"bundle":   summary(t, caDER),
+				}},
+				"federatedBundles": map[string]interface{}{
+					"spiffe://federated.test": summary(t, federatedDER),
+				},
+			},
+		},
+		{
+			name:          "X509SVIDResponse with proto names",
+			m:             &workload.X509SVIDResponse{FederatedBundles: map[string][]byte{"spiffe://federated.test": federatedDER}},
+			useProtoNames: true,
+			want: map[string]interface{}{
+				"federated_bundles": map[string]interface{}{
+					"spiffe://federated.test": summary(t, federatedDER),
+				},
+			},
+		},
+		{
+			name: "JWKS bundles are left as is",
+			m:    &workload.JWTBundlesResponse{Bundles: map[string][]byte{"spiffe://example.org": jwks}},
+			want: map[string]interface{}{
+				"bundles": map[string]interface{}{
+					"spiffe://example.org": base64.StdEncoding.EncodeToString(jwks),
+				},
+			},
+		},
+		{
+			name: "Bundle",
+			m: &types.Bundle{
+				TrustDomain:     "example.org",
+				X509Authorities: []*types.X509Certificate{{Asn1: caDER}},
+				JwtAuthorities:  []*types.JWTKey{{KeyId: "kid", PublicKey: publicKeyDER}},
+			},
+			want: map[string]interface{}{
+				"trustDomain":     "example.org",
+				"x509Authorities": []interface{}{map[string]interface{}{"asn1": summary(t, caDER)}},
+				"jwtAuthorities": []interface{}{map[string]interface{}{
+					"keyId": "kid",
+					"publicKey": map[string]interface{}{
+						"type":              describePublicKey(publicKeyDER),
+						"sha256Fingerprint": fingerprint(publicKeyDER),
+					},
+				}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonOut, err := protojson.MarshalOptions{UseProtoNames: tt.useProtoNames}.Marshal(tt.m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := decodeJSON(jsonOut)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decodeCerts(tt.m.ProtoReflect(), decoded, tt.useProtoNames)
+			if !reflect.DeepEqual(decoded, tt.want) {
+				t.Fatalf("expected %#v; got %#v", tt.want, decoded)
+			}
+		})
+	}
+}
+
+func summary(t *testing.T, der []byte) map[string]interface{} {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return summarizeCertificate(cert)
+}
+
+func createTestCertificate(t *testing.T, commonName, spiffeID string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         spiffeID == "",
+	}
+	if spiffeID != "" {
+		u, err := url.Parse(spiffeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.URIs = []*url.URL{u}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/yaml"
+)
+
+// requestFormats are the formats in which requests may be given.
+var requestFormats = []string{outputJSON, outputYAML, outputPrototext, outputBinpb}
+
+// requestToJSON converts a request in the given format to protojson. Empty
+// input is returned as is.
+func requestToJSON(format string, in []byte, newRequest func() proto.Message) ([]byte, error) {
+	if format != outputBinpb && len(bytes.TrimSpace(in)) == 0 {
+		return nil, nil
+	}
+	switch format {
+	case outputJSON:
+		return in, nil
+	case outputYAML:
+		jsonIn, err := yaml.YAMLToJSON(in)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML request: %v", err)
+		}
+		return jsonIn, nil
+	case outputPrototext:
+		req := newRequest()
+		if err := prototext.Unmarshal(in, req); err != nil {
+			return nil, fmt.Errorf("invalid prototext request: %v", err)
+		}
+		return protojson.Marshal(req)
+	case outputBinpb:
+		if len(in) == 0 {
+			return nil, nil
+		}
+		req := newRequest()
+		if err := proto.Unmarshal(in, req); err != nil {
+			return nil, fmt.Errorf("invalid binpb request: %v", err)
+		}
+		return protojson.Marshal(req)
+	default:
+		return nil, fmt.Errorf("unknown input format %q (expected one of %s)", format, strings.Join(requestFormats, ", "))
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"sigs.k8s.io/yaml"
+)
+
+const outputCompact = "compact"
+
+// outputFormats are the formats in which responses may be rendered.
+var outputFormats = []string{outputJSON, outputCompact, outputYAML, outputPrototext, outputBinpb, outputTable}
+
+// outputFlags configure how the response of an RPC is rendered.
+type outputFlags struct {
+	format          string
+	outFormat       string
+	jsonPath        string
+	fields          []string
+	emitUnpopulated bool
+	useProtoNames   bool
+	decodeCerts     bool
+}
+
+func addOutputFlags(flags *pflag.FlagSet, of *outputFlags) {
+	flags.StringVarP(&of.format, "output", "o", "", "Output format: json (default), compact, yaml, prototext, binpb or table")
+	// --out-format is the name used by the convert and generate commands.
+	flags.StringVarP(&of.outFormat, "out-format", "", "", "Alias of --output")
+	flags.StringVarP(&of.jsonPath, "jsonpath", "", "", "Path expression (e.g. .entries[].id) selecting the part of the response to output")
+	flags.StringArrayVarP(&of.fields, "field", "", nil, "Path of a table column relative to each row, e.g. spiffe_id (repeatable; table output only)")
+	flags.BoolVarP(&of.emitUnpopulated, "emit-unpopulated", "", false, "Include fields with default values in json, compact, yaml and table output")
+	flags.BoolVarP(&of.useProtoNames, "use-proto-names", "", false, "Use protobuf field names (e.g. spiffe_id) instead of lowerCamelCase names in json, compact and yaml output")
+	flags.BoolVarP(&of.decodeCerts, "decode-certs", "", false, "Replace base64 DER certificates and public keys in the response with summaries (subject, SPIFFE ID, validity, fingerprint)")
+}
+
+// check returns an error if the flags cannot be used together. It is called
+// before the RPC is issued.
+func (of *outputFlags) check() error {
+	switch {
+	case of.format != "" && of.outFormat != "" && of.format != of.outFormat:
+		return fmt.Errorf("--output %s and --out-format %s conflict", of.format, of.outFormat)
+	case of.format == "" && of.outFormat != "":
+		of.format = of.outFormat
+	case of.format == "":
+		of.format = outputJSON
+	}
+	if err := checkOutputFormat(of.format, outputFormats...); err != nil {
+		return err
+	}
+	if of.format == outputPrototext || of.format == outputBinpb {
+		switch {
+		case of.jsonPath != "":
+			return fmt.Errorf("--jsonpath cannot be used with --output %s", of.format)
+		case of.decodeCerts:
+			return fmt.Errorf("--decode-certs cannot be used with --output %s", of.format)
+		}
+	}
+	if len(of.fields) > 0 && of.format != outputTable {
+		return fmt.Errorf("--field requires --output %s", outputTable)
+	}
+	return nil
+}
+
+func (of *outputFlags) marshalOptions() protojson.MarshalOptions {
+	return protojson.MarshalOptions{
+		EmitUnpopulated: of.emitUnpopulated,
+		UseProtoNames:   of.useProtoNames,
+	}
+}
+
+// render renders the response in the output format.
+func (of *outputFlags) render(m proto.Message) ([]byte, error) {
+	switch {
+	case of.format == outputPrototext:
+		return prototext.MarshalOptions{Multiline: true}.Marshal(m)
+	case of.format == outputBinpb:
+		return proto.Marshal(m)
+	case of.format == outputTable && of.jsonPath == "":
+		return of.messageTable(m)
+	case of.jsonPath != "" || of.decodeCerts:
+		value, err := of.decode(m.ProtoReflect())
+		if err != nil {
+			return nil, err
+		}
+		if of.jsonPath != "" {
+			if value, err = evalPath(value, of.jsonPath); err != nil {
+				return nil, fmt.Errorf("invalid --jsonpath: %v", err)
+			}
+		}
+		return of.renderValue(value)
+	}
+
+	options := of.marshalOptions()
+	if of.format == outputJSON {
+		options.Multiline = true
+		return []byte(options.Format(m)), nil
+	}
+	jsonOut, err := options.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if of.format == outputYAML {
+		return yaml.JSONToYAML(jsonOut)
+	}
+	// protojson randomizes whitespace so the output is compacted.
+	out := new(bytes.Buffer)
+	if err := json.Compact(out, jsonOut); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// decode returns the decoded protojson of a message, with certificates and
+// public keys summarized if requested.
+func (of *outputFlags) decode(m protoreflect.Message) (interface{}, error) {
+	jsonOut, err := of.marshalOptions().Marshal(m.Interface())
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeJSON(jsonOut)
+	if err != nil {
+		return nil, err
+	}
+	if of.decodeCerts {
+		decodeCerts(m, decoded, of.useProtoNames)
+	}
+	return decoded, nil
+}
+
+// renderValue renders a decoded value (e.g. one selected by --jsonpath).
+func (of *outputFlags) renderValue(value interface{}) ([]byte, error) {
+	switch of.format {
+	case outputJSON:
+		out, err := json.MarshalIndent(value, "", "  ")
+		return append(out, '\n'), err
+	case outputCompact:
+		out, err := json.Marshal(value)
+		return append(out, '\n'), err
+	case outputYAML:
+		return yaml.Marshal(value)
+	}
+
+	// Table output: objects are rows and anything else is printed as is,
+	// one value per line.
+	var values []interface{}
+	if list, ok := value.([]interface{}); ok {
+		values = list
+	} else if value != nil {
+		values = []interface{}{value}
+	}
+	var rows []interface{}
+	out := new(bytes.Buffer)
+	for _, v := range values {
+		if object, ok := v.(map[string]interface{}); ok {
+			if _, ok := formatIdentifier(object); !ok {
+				rows = append(rows, v)
+				continue
+			}
+		}
+		fmt.Fprintln(out, formatCell(v))
+	}
+	if len(rows) == 0 {
+		return out.Bytes(), nil
+	}
+	columns := of.fields
+	if len(columns) == 0 {
+		columns = objectKeys(rows)
+	}
+	if err := writeValueTable(out, rows, columns, !of.emitUnpopulated); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// messageTable renders a message as a table. The rows of a response are the
+// elements of its first repeated message field (e.g. entries or agents). The
+// row of any other message (e.g. the Entry returned by GetEntry), or of a
+// response without such a field, is the message itself. Unless --field is
+// given, the columns are the fields of the row message that are populated in
+// at least one row.
+func (of *outputFlags) messageTable(m proto.Message) ([]byte, error) {
+	rowMessages := []protoreflect.Message{m.ProtoReflect()}
+	fields := m.ProtoReflect().Descriptor().Fields()
+	isResponse := strings.HasSuffix(string(m.ProtoReflect().Descriptor().Name()), "Response")
+	for i := 0; isResponse && i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !fd.IsList() || fd.Message() == nil {
+			continue
+		}
+		list := m.ProtoReflect().Get(fd).List()
+		rowMessages = make([]protoreflect.Message, 0, list.Len())
+		for j := 0; j < list.Len(); j++ {
+			rowMessages = append(rowMessages, list.Get(j).Message())
+		}
+		fields = fd.Message().Fields()
+		break
+	}
+
+	rows := make([]interface{}, 0, len(rowMessages))
+	for _, rowMessage := range rowMessages {
+		row, err := of.decode(rowMessage)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	columns := of.fields
+	if len(columns) == 0 {
+		for i := 0; i < fields.Len(); i++ {
+			columns = append(columns, string(fields.Get(i).Name()))
+		}
+	}
+	out := new(bytes.Buffer)
+	if err := writeValueTable(out, rows, columns, !of.emitUnpopulated); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeValueTable writes a table of decoded JSON objects, with a column for
+// each path. Columns that are empty in every row are omitted if omitEmpty is
+// set.
+func writeValueTable(out *bytes.Buffer, rows []interface{}, columns []string, omitEmpty bool) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	cells := make([][]string, len(rows))
+	var headers []string
+	var kept []int
+	for c, column := range columns {
+		path := column
+		if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") && !strings.HasPrefix(path, "$") {
+			path = "." + path
+		}
+		populated := false
+		for r, row := range rows {
+			value, err := evalPath(row, path)
+			if err != nil {
+				return fmt.Errorf("invalid --field %q: %v", column, err)
+			}
+			cell := formatCell(value)
+			cells[r] = append(cells[r], cell)
+			populated = populated || cell != ""
+		}
+		if populated || !omitEmpty {
+			headers = append(headers, columnHeader(column))
+			kept = append(kept, c)
+		}
+	}
+
+	tableRows := make([][]string, 0, len(rows))
+	for _, rowCells := range cells {
+		tableRow := make([]string, 0, len(kept))
+		for _, c := range kept {
+			tableRow = append(tableRow, rowCells[c])
+		}
+		tableRows = append(tableRows, tableRow)
+	}
+	return writeTable(out, headers, tableRows)
+}
+
+// columnHeader returns the header of the column for a path, e.g. SPIFFE ID
+// for spiffe_id.
+func columnHeader(path string) string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.NewReplacer("[]", "", "[*]", "", `["`, " ", `"]`, "", ".", " ", "_", " ").Replace(path)
+	return strings.ToUpper(strings.TrimSpace(path))
+}
+
+// formatCell formats a decoded JSON value as a table cell. SPIFFE IDs and
+// selectors are shown in their usual string forms.
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, elem := range v {
+			parts = append(parts, formatCell(elem))
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		if s, ok := formatIdentifier(v); ok {
+			return s
+		}
+		if len(v) == 0 {
+			return ""
+		}
+		out, _ := json.Marshal(v)
+		return string(out)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatIdentifier formats a decoded types.SPIFFEID or types.Selector in its
+// string form. It returns false if the object is neither.
+func formatIdentifier(v map[string]interface{}) (string, bool) {
+	if len(v) != 2 {
+		return "", false
+	}
+	if path, ok := v["path"].(string); ok {
+		td, ok := v["trustDomain"].(string)
+		if !ok {
+			td, ok = v["trust_domain"].(string)
+		}
+		return "spiffe://" + td + path, ok
+	}
+	t, typeOK := v["type"].(string)
+	value, valueOK := v["value"].(string)
+	return t + ":" + value, typeOK && valueOK
+}
+
+// objectKeys returns the sorted union of the keys of decoded JSON objects.
+func objectKeys(objects []interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, object := range objects {
+		for key := range object.(map[string]interface{}) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
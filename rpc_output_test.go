@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestOutputFlagsCheck(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		flags      outputFlags
+		wantFormat string
+		wantErr    string
+	}{
+		{name: "default", wantFormat: outputJSON},
+		{name: "output", flags: outputFlags{format: outputYAML}, wantFormat: outputYAML},
+		{name: "out-format alias", flags: outputFlags{outFormat: outputTable}, wantFormat: outputTable},
+		{name: "both agree", flags: outputFlags{format: outputTable, outFormat: outputTable}, wantFormat: outputTable},
+		{name: "both conflict", flags: outputFlags{format: outputTable, outFormat: outputJSON}, wantErr: "--output table and --out-format json conflict"},
+		{name: "unknown format", flags: outputFlags{format: "xml"}, wantErr: `unknown output format "xml"`},
+		{name: "jsonpath with binpb", flags: outputFlags{format: outputBinpb, jsonPath: ".id"}, wantErr: "--jsonpath cannot be used with --output binpb"},
+		{name: "field without table", flags: outputFlags{fields: []string{"id"}}, wantErr: "--field requires --output table"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.flags.check()
+			assertErrorContains(t, err, tt.wantErr)
+			if err == nil && tt.flags.format != tt.wantFormat {
+				t.Fatalf("expected format %q; got %q", tt.wantFormat, tt.flags.format)
+			}
+		})
+	}
+}
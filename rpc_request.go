@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
+)
+
+// requestFlags configure how the request of an RPC is built.
+type requestFlags struct {
+	format string
+	file   string
+	vars   []string
+	sets   []string
+}
+
+func addRequestFlags(flags *pflag.FlagSet, rf *requestFlags) {
+	flags.StringVarP(&rf.format, "in-format", "", outputJSON, "Format of the request (json, yaml, prototext or binpb)")
+	flags.StringVarP(&rf.file, "request-file", "", "", "File containing the request (instead of stdin; - reads it from stdin), expanded as a Go template and with ${NAME} references substituted")
+	flags.StringArrayVarP(&rf.vars, "var", "", nil, "Variable of the form NAME=VALUE for the request file (repeatable); ${NAME} falls back to the environment")
+	flags.StringArrayVarP(&rf.sets, "set", "", nil, "Override of the form path.to.field=VALUE applied to the request (repeatable); VALUE is a string unless it is true, false, null or a JSON object or array; stdin is not read unless --request-file - is given")
+}
+
+// readsStdin returns true if the request is read from stdin. It is when the
+// request file is "-". Otherwise it is not when a request file or overrides
+// are given, or when stdin is a terminal, so that requests built entirely
+// from flags do not wait for input that never comes (e.g. in CI, where stdin
+// is neither a terminal nor closed).
+func (rf *requestFlags) readsStdin() bool {
+	if rf.file == "-" {
+		return true
+	}
+	if rf.file != "" || len(rf.sets) > 0 {
+		return false
+	}
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return false
+	}
+	return true
+}
+
+// build returns the JSON request, converting the request file or stdin from
+// the input format. Without a request file or overrides, the converted
+// request is returned unchanged.
+func (rf *requestFlags) build(stdin []byte, newRequest func() proto.Message) ([]byte, error) {
+	toJSON := func(in []byte) ([]byte, error) {
+		return requestToJSON(rf.format, in, newRequest)
+	}
+	if rf.file == "" && len(rf.sets) == 0 {
+		return toJSON(stdin)
+	}
+
+	vars, err := parseRequestVars(rf.vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var request interface{}
+	switch {
+	case rf.file != "":
+		text := stdin
+		if rf.file != "-" {
+			if text, err = os.ReadFile(rf.file); err != nil {
+				return nil, fmt.Errorf("unable to read request file: %v", err)
+			}
+		}
+		if request, err = expandRequest(rf.file, text, vars, toJSON); err != nil {
+			return nil, err
+		}
+	default:
+		jsonIn, err := toJSON(stdin)
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(jsonIn)) == 0 {
+			request = map[string]interface{}{}
+		} else if request, err = decodeJSON(jsonIn); err != nil {
+			return nil, fmt.Errorf("invalid request: %v", err)
+		}
+	}
+
+	for _, set := range rf.sets {
+		path, raw, ok := strings.Cut(set, "=")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid --set %q: expected path.to.field=VALUE", set)
+		}
+		if request, err = setPath(request, path, parseSetValue(raw)); err != nil {
+			return nil, fmt.Errorf("invalid --set %q: %v", set, err)
+		}
+	}
+	return json.Marshal(request)
+}
+
+func parseRequestVars(pairs []string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected NAME=VALUE", pair)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// expandRequest expands the text of a request file as a Go template,
+// converts it to JSON and substitutes ${NAME} references in its strings.
+func expandRequest(path string, text []byte, vars map[string]interface{}, toJSON func([]byte) ([]byte, error)) (interface{}, error) {
+	tmpl, err := template.New(path).Option("missingkey=error").Funcs(template.FuncMap{
+		"env": os.Getenv,
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request template: %v", err)
+	}
+	expanded := new(bytes.Buffer)
+	if err := tmpl.Execute(expanded, vars); err != nil {
+		return nil, fmt.Errorf("unable to expand request template: %v", err)
+	}
+
+	jsonIn, err := toJSON(expanded.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(bytes.TrimSpace(jsonIn)) == 0 {
+		jsonIn = []byte("{}")
+	}
+	request, err := decodeJSON(jsonIn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request in %s: %v", path, err)
+	}
+	return substituteVars(request, lookupVar(vars))
+}
+
+// parseSetValue parses the value of a --set override. Values are strings
+// (which protojson also accepts for numeric fields) unless they are JSON
+// literals that cannot be given as strings.
+func parseSetValue(raw string) interface{} {
+	trimmed := strings.TrimSpace(raw)
+	switch {
+	case trimmed == "true", trimmed == "false", trimmed == "null",
+		strings.HasPrefix(trimmed, "{"), strings.HasPrefix(trimmed, "["):
+		if v, err := decodeJSON([]byte(trimmed)); err == nil {
+			return v
+		}
+	}
+	return raw
+}
+
+// setPath sets the value at a path of the form a.b[0].c within a decoded
+// JSON value, creating objects and appending array elements as needed. An
+// existing field may be named by its protobuf name (e.g. spiffe_id) even when
+// the JSON uses the lowerCamelCase name (e.g. spiffeId).
+func setPath(root interface{}, path string, value interface{}) (interface{}, error) {
+	if path == "" {
+		return value, nil
+	}
+
+	if strings.HasPrefix(path, "[") {
+		end := strings.Index(path, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated index in path %q", path)
+		}
+		index, err := strconv.Atoi(path[1:end])
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid index %q in path", path[1:end])
+		}
+		rest := strings.TrimPrefix(path[end+1:], ".")
+
+		var list []interface{}
+		switch v := root.(type) {
+		case nil:
+		case []interface{}:
+			list = v
+		default:
+			return nil, fmt.Errorf("cannot index %s", jsonTypeName(root))
+		}
+		switch {
+		case index < len(list):
+			elem, err := setPath(list[index], rest, value)
+			if err != nil {
+				return nil, err
+			}
+			list[index] = elem
+		case index == len(list):
+			elem, err := setPath(nil, rest, value)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, elem)
+		default:
+			return nil, fmt.Errorf("index %d out of range", index)
+		}
+		return list, nil
+	}
+
+	end := strings.IndexAny(path, ".[")
+	if end < 0 {
+		end = len(path)
+	}
+	name := path[:end]
+	rest := strings.TrimPrefix(path[end:], ".")
+	if name == "" {
+		return nil, fmt.Errorf("empty field name in path")
+	}
+
+	var obj map[string]interface{}
+	switch v := root.(type) {
+	case nil:
+		obj = map[string]interface{}{}
+	case map[string]interface{}:
+		obj = v
+	default:
+		return nil, fmt.Errorf("cannot set field %q of %s", name, jsonTypeName(root))
+	}
+	if _, ok := obj[name]; !ok {
+		if camel := lowerCamelCase(name); camel != name {
+			if _, ok := obj[camel]; ok {
+				name = camel
+			}
+		}
+	}
+	fv, err := setPath(obj[name], rest, value)
+	if err != nil {
+		return nil, err
+	}
+	obj[name] = fv
+	return obj, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	defaultRetryCodes = []string{
+		codes.Unavailable.String(),
+		codes.DeadlineExceeded.String(),
+		codes.ResourceExhausted.String(),
+	}
+
+	// idempotentMethodPrefixes are the prefixes of RPC method names that
+	// only read state and are therefore safe to re-issue.
+	idempotentMethodPrefixes = []string{
+		"Count",
+		"Fetch",
+		"Get",
+		"List",
+		"Validate",
+	}
+)
+
+type retryConfig struct {
+	retries            int
+	backoff            time.Duration
+	codes              []string
+	retryNonIdempotent bool
+}
+
+func addRetryFlags(flags *pflag.FlagSet, config *retryConfig) {
+	flags.IntVarP(&config.retries, "retries", "", 0, "Number of times to retry the RPC on a retryable failure")
+	flags.DurationVarP(&config.backoff, "retry-backoff", "", 250*time.Millisecond, "Initial backoff between retries (doubled after each retry)")
+	flags.StringSliceVarP(&config.codes, "retry-codes", "", defaultRetryCodes, "gRPC status codes that are retried")
+	flags.BoolVarP(&config.retryNonIdempotent, "retry-non-idempotent", "", false, "Retry RPCs that are not known to be safe to retry")
+}
+
+func (cmd *rpcCommand) callWithRetries(ctx context.Context, jsonIn []byte) (proto.Message, error) {
+	retryCodes, err := parseCodes(cmd.config.retry.codes)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := cmd.config.retry.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := cmd.call(ctx, jsonIn)
+		if err == nil || attempt >= cmd.config.retry.retries || ctx.Err() != nil || !cmd.shouldRetry(err, retryCodes) {
+			return resp, err
+		}
+
+		fmt.Fprintf(cmd.stderr, "Attempt %d failed; retrying in %s: %v\n", attempt+1, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+func (cmd *rpcCommand) shouldRetry(err error, retryCodes map[codes.Code]bool) bool {
+	// Nothing was sent to the server if the connection could not be
+	// established, so it is safe to try again if the failure is transient
+	// (e.g. the connection was refused while the server restarts).
+	var de dialError
+	if errors.As(err, &de) {
+		return status.Code(de.err) == codes.Unavailable
+	}
+
+	var re rpcError
+	if !errors.As(err, &re) || !retryCodes[re.st.Code()] {
+		return false
+	}
+	if !cmd.isUnary() {
+		return false
+	}
+	return cmd.config.retry.retryNonIdempotent || isIdempotentMethod(cmd.methodName)
+}
+
+func isIdempotentMethod(methodName string) bool {
+	for _, prefix := range idempotentMethodPrefixes {
+		if strings.HasPrefix(methodName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCodes parses gRPC status code names. Names are matched without regard
+// to case or underscores (e.g. "Unavailable", "DEADLINE_EXCEEDED").
+func parseCodes(names []string) (map[codes.Code]bool, error) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+	}
+
+	out := make(map[codes.Code]bool)
+next:
+	for _, name := range names {
+		for c := codes.OK; c <= codes.Unauthenticated; c++ {
+			if normalize(c.String()) == normalize(name) {
+				out[c] = true
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("unknown status code %q", name)
+	}
+	return out, nil
+}
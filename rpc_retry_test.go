@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseCodes(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		names   []string
+		want    map[codes.Code]bool
+		wantErr string
+	}{
+		{
+			name:  "none",
+			names: nil,
+			want:  map[codes.Code]bool{},
+		},
+		{
+			name:  "camel case",
+			names: []string{"Unavailable", "DeadlineExceeded"},
+			want:  map[codes.Code]bool{codes.Unavailable: true, codes.DeadlineExceeded: true},
+		},
+		{
+			name:  "upper snake case",
+			names: []string{"RESOURCE_EXHAUSTED", "ok"},
+			want:  map[codes.Code]bool{codes.ResourceExhausted: true, codes.OK: true},
+		},
+		{
+			name:    "unknown",
+			names:   []string{"Unavailable", "Flaky"},
+			wantErr: `unknown status code "Flaky"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCodes(tt.names)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q; got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v; got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	retryCodes := map[codes.Code]bool{codes.Unavailable: true}
+	for _, tt := range []struct {
+		name               string
+		methodName         string
+		retryNonIdempotent bool
+		err                error
+		want               bool
+	}{
+		{
+			name:       "transient dial failure",
+			methodName: "BatchCreateEntry",
+			err:        dialError{err: status.Error(codes.Unavailable, "connection refused")},
+			want:       true,
+		},
+		{
+			name:       "permanent dial failure",
+			methodName: "ListEntries",
+			err:        dialError{err: status.Error(codes.Unauthenticated, "bad certificate")},
+		},
+		{
+			name:       "configuration error",
+			methodName: "ListEntries",
+			err:        errors.New("unable to load SVID"),
+		},
+		{
+			name:       "idempotent",
+			methodName: "ListEntries",
+			err:        newRPCError("ListEntries", status.Error(codes.Unavailable, "")),
+			want:       true,
+		},
+		{
+			name:       "code not retried",
+			methodName: "ListEntries",
+			err:        newRPCError("ListEntries", status.Error(codes.NotFound, "")),
+		},
+		{
+			name:       "not idempotent",
+			methodName: "BatchCreateEntry",
+			err:        newRPCError("BatchCreateEntry", status.Error(codes.Unavailable, "")),
+		},
+		{
+			name:               "not idempotent but allowed",
+			methodName:         "BatchCreateEntry",
+			retryNonIdempotent: true,
+			err:                newRPCError("BatchCreateEntry", status.Error(codes.Unavailable, "")),
+			want:               true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &rpcCommand{
+				newClientFn: reflect.ValueOf(entryv1.NewEntryClient),
+				methodName:  tt.methodName,
+				config:      &rpcConfig{retry: retryConfig{retryNonIdempotent: tt.retryNonIdempotent}},
+			}
+			if got := cmd.shouldRetry(tt.err, retryCodes); got != tt.want {
+				t.Fatalf("expected %t; got %t", tt.want, got)
+			}
+		})
+	}
+}
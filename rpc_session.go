@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// rpcSession issues RPCs against the APIs in rpcAPIs. The connection for
+// each API is established on first use and reused until the session is
+// closed.
+type rpcSession struct {
+	serverConfig rpcConfig
+	agentConfig  rpcConfig
+	adminConfig  rpcConfig
+
+	conns map[string]*grpc.ClientConn
+}
+
+func addSessionFlags(flags *pflag.FlagSet, s *rpcSession) {
+	addServerFlags(flags, &s.serverConfig)
+	addConnectionFlags(flags, &s.serverConfig)
+	flags.StringVarP(&s.agentConfig.udsAddr, "agent-uds-addr", "", defaultWorkloadAPIAddr(), "agent UDS address (defaults to $SPIFFE_ENDPOINT_SOCKET)")
+	flags.StringVarP(&s.adminConfig.udsAddr, "admin-uds-addr", "", "unix:///tmp/spire-agent/private/admin.sock", "agent admin UDS address")
+}
+
+// open prepares the session for use once flags have been parsed.
+func (s *rpcSession) open() {
+	for _, config := range []*rpcConfig{&s.agentConfig, &s.adminConfig} {
+		config.keepaliveTime = s.serverConfig.keepaliveTime
+		config.keepaliveTimeout = s.serverConfig.keepaliveTimeout
+	}
+	setWorkloadAPIHeader(&s.agentConfig)
+	s.conns = make(map[string]*grpc.ClientConn)
+}
+
+func (s *rpcSession) Close() {
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	for _, config := range []*rpcConfig{&s.serverConfig, &s.agentConfig, &s.adminConfig} {
+		config.closeSource()
+	}
+}
+
+// Call invokes the method (by dasherized name) on the API (by dasherized
+// name) with the given JSON request.
+func (s *rpcSession) Call(ctx context.Context, apiName, methodName string, jsonIn []byte) (proto.Message, error) {
+	api, ok := lookupRPCAPI(apiName)
+	if !ok {
+		return nil, fmt.Errorf("unknown API %q", apiName)
+	}
+	cmd, ok := s.rpcCommand(api, methodName)
+	if !ok {
+		return nil, fmt.Errorf("unknown %s method %q", api.name, methodName)
+	}
+
+	conn, ok := s.conns[api.name]
+	if !ok {
+		var err error
+		conn, err = cmd.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.conns[api.name] = conn
+	}
+	return cmd.invoke(ctx, conn, jsonIn)
+}
+
+func (s *rpcSession) rpcCommand(api rpcAPI, dasherizedMethodName string) (*rpcCommand, bool) {
+	config := &s.serverConfig
+	switch api.endpoint {
+	case workloadEndpoint:
+		config = &s.agentConfig
+	case agentAdminEndpoint:
+		config = &s.adminConfig
+	}
+	for _, methodName := range rpcMethodNames(api.clientFn) {
+		if dasherizeAPIName(methodName) == dasherizedMethodName {
+			return &rpcCommand{
+				newClientFn: reflect.ValueOf(api.clientFn),
+				methodName:  methodName,
+				config:      config,
+			}, true
+		}
+	}
+	return nil, false
+}
+
+func rpcAPINames() []string {
+	names := make([]string, 0, len(rpcAPIs))
+	for _, api := range rpcAPIs {
+		names = append(names, dasherizeAPIName(api.name))
+	}
+	return names
+}
+
+func lookupRPCAPI(dasherizedName string) (rpcAPI, bool) {
+	for _, api := range rpcAPIs {
+		if dasherizeAPIName(api.name) == dasherizedName {
+			return api, true
+		}
+	}
+	return rpcAPI{}, false
+}
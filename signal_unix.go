@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+func init() {
+	signalNames["USR1"] = syscall.SIGUSR1
+	signalNames["USR2"] = syscall.SIGUSR2
+}
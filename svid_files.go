@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+const (
+	svidFileName          = "svid.pem"
+	svidKeyFileName       = "svid_key.pem"
+	bundleFileName        = "bundle.pem"
+	federatedBundlePrefix = "federated_bundle_"
+	dataDirLink           = "..data"
+
+	certFileMode = 0644
+	keyFileMode  = 0600
+)
+
+// writeX509Files writes the default X509-SVID in the X509 context, its key,
+// the bundle for its trust domain and any federated bundles to dir. It
+// returns the paths of the files written. The files are swapped in together
+// (see writeFileSet), so readers never see a certificate next to the key of
+// another update, and bundles of trust domains that are no longer federated
+// are removed.
+func writeX509Files(dir string, x509Ctx *workloadapi.X509Context) ([]string, error) {
+	svid := x509Ctx.DefaultSVID()
+	certsPEM, keyPEM, err := svid.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal X509-SVID: %v", err)
+	}
+
+	files := []namedFile{
+		{name: svidFileName, data: certsPEM, mode: certFileMode},
+		{name: svidKeyFileName, data: keyPEM, mode: keyFileMode},
+	}
+	for _, bundle := range x509Ctx.Bundles.Bundles() {
+		bundlePEM, err := bundle.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal bundle for %q: %v", bundle.TrustDomain(), err)
+		}
+		name := bundleFileName
+		if bundle.TrustDomain() != svid.ID.TrustDomain() {
+			name = federatedBundlePrefix + bundle.TrustDomain().String() + ".pem"
+		}
+		files = append(files, namedFile{name: name, data: bundlePEM, mode: certFileMode})
+	}
+	return writeFileSet(dir, files)
+}
+
+type namedFile struct {
+	name string
+	data []byte
+	mode os.FileMode
+}
+
+// writeFileSet writes files to a new hidden data directory in dir and swaps
+// it in by atomically replacing the dataDirLink symlink. Each file in dir is
+// a symlink through dataDirLink, so all of them change at once. Links to
+// files of the previous set that are not in this one are removed, as is the
+// previous data directory. It returns the paths of the files in dir.
+func writeFileSet(dir string, files []namedFile) (_ []string, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	linkPath := filepath.Join(dir, dataDirLink)
+	prevDataDir, _ := os.Readlink(linkPath)
+
+	dataDir, err := os.MkdirTemp(dir, dataDirLink+"_")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Once swapped in, the data directory is in use.
+		if err != nil && readlink(linkPath) != filepath.Base(dataDir) {
+			os.RemoveAll(dataDir)
+		}
+	}()
+	if err := os.Chmod(dataDir, 0755); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := writeFileAtomic(filepath.Join(dataDir, f.name), f.data, f.mode); err != nil {
+			return nil, err
+		}
+	}
+	if err := replaceSymlink(linkPath, filepath.Base(dataDir)); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	names := make(map[string]bool)
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if target := filepath.Join(dataDirLink, f.name); readlink(path) != target {
+			if err := replaceSymlink(path, target); err != nil {
+				return nil, err
+			}
+		}
+		names[f.name] = true
+		paths = append(paths, path)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		target := readlink(filepath.Join(dir, entry.Name()))
+		if strings.HasPrefix(target, dataDirLink+string(filepath.Separator)) && !names[entry.Name()] {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if strings.HasPrefix(prevDataDir, dataDirLink+"_") && !strings.ContainsRune(prevDataDir, filepath.Separator) {
+		if err := os.RemoveAll(filepath.Join(dir, prevDataDir)); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// replaceSymlink atomically points the symlink at path to target, replacing
+// whatever is at path.
+func replaceSymlink(path, target string) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// readlink returns the target of the symlink at path, or an empty string if
+// path is not a symlink.
+func readlink(path string) string {
+	target, _ := os.Readlink(path)
+	return target
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as
+// path and renames it into place, so readers never observe a partially
+// written file.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		existing []byte
+		data     []byte
+		mode     os.FileMode
+	}{
+		{
+			name: "new certificate file",
+			data: []byte("certs"),
+			mode: certFileMode,
+		},
+		{
+			name: "new key file",
+			data: []byte("key"),
+			mode: keyFileMode,
+		},
+		{
+			name:     "replaces existing file",
+			existing: []byte("old contents that are longer"),
+			data:     []byte("new"),
+			mode:     keyFileMode,
+		},
+		{
+			name: "empty",
+			data: []byte{},
+			mode: certFileMode,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "svid.pem")
+			if tt.existing != nil {
+				if err := os.WriteFile(path, tt.existing, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := writeFileAtomic(path, tt.data, tt.mode); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(tt.data) {
+				t.Fatalf("expected %q; got %q", tt.data, got)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != tt.mode {
+				t.Fatalf("expected mode %s; got %s", tt.mode, info.Mode().Perm())
+			}
+			// The temporary file is renamed into place.
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("expected only %s in the directory; got %d entries", path, len(entries))
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomicMissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "svid.pem")
+	if err := writeFileAtomic(path, []byte("certs"), certFileMode); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestWriteFileSet(t *testing.T) {
+	dir := t.TempDir()
+	write := func(files ...namedFile) {
+		t.Helper()
+		paths, err := writeFileSet(dir, files)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(paths) != len(files) {
+			t.Fatalf("expected %d paths; got %d", len(files), len(paths))
+		}
+	}
+	assertFile := func(name, want string, mode os.FileMode) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("expected %q in %s; got %q", want, name, got)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Fatalf("expected mode %s for %s; got %s", mode, name, info.Mode().Perm())
+		}
+	}
+	assertEntries := func(want int) {
+		t.Helper()
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != want {
+			t.Fatalf("expected %d entries; got %d", want, len(entries))
+		}
+	}
+
+	// A file left by an earlier version is replaced by a link.
+	if err := os.WriteFile(filepath.Join(dir, svidFileName), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	federatedBundle := federatedBundlePrefix + "other.org.pem"
+	write(
+		namedFile{name: svidFileName, data: []byte("certs1"), mode: certFileMode},
+		namedFile{name: svidKeyFileName, data: []byte("key1"), mode: keyFileMode},
+		namedFile{name: federatedBundle, data: []byte("bundle1"), mode: certFileMode},
+	)
+	assertFile(svidFileName, "certs1", certFileMode)
+	assertFile(svidKeyFileName, "key1", keyFileMode)
+	assertFile(federatedBundle, "bundle1", certFileMode)
+	// Three links, the data directory link and the data directory.
+	assertEntries(5)
+
+	write(
+		namedFile{name: svidFileName, data: []byte("certs2"), mode: certFileMode},
+		namedFile{name: svidKeyFileName, data: []byte("key2"), mode: keyFileMode},
+	)
+	assertFile(svidFileName, "certs2", certFileMode)
+	assertFile(svidKeyFileName, "key2", keyFileMode)
+	// The federated bundle and the previous data directory are removed.
+	if _, err := os.Lstat(filepath.Join(dir, federatedBundle)); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed; got %v", federatedBundle, err)
+	}
+	assertEntries(4)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable     = "table"
+	outputJSON      = "json"
+	outputYAML      = "yaml"
+	outputPrototext = "prototext"
+	outputBinpb     = "binpb"
+)
+
+// checkOutputFormat returns an error if format is not one of the choices.
+func checkOutputFormat(format string, choices ...string) error {
+	for _, choice := range choices {
+		if format == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q (expected one of %s)", format, strings.Join(choices, ", "))
+}
+
+// writeTable writes rows as aligned columns beneath the headers.
+func writeTable(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}